@@ -3,50 +3,187 @@
 package main
 
 import (
+	"dito/src/ast"
+	"dito/src/compiler"
 	"dito/src/eval"
 	"dito/src/object"
 	"dito/src/parser"
 	"dito/src/repl"
+	"dito/src/resolver"
 	"dito/src/scanner"
+	"dito/src/vm"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 )
 
+// https://gobyexample.com/command-line-flags
+var useVM = flag.Bool("vm", false, "run files on the bytecode vm instead of the tree walking evaluator")
+
+var importPath = flag.String("path", "",
+	"list of directories separated by '"+string(os.PathListSeparator)+"' to search for imports")
+
 func main() {
-	args := os.Args[1:] // args without program.
-	// https://gobyexample.com/command-line-flags
+	flag.Parse()
+	args := flag.Args() // args without program or flags.
+	if len(args) > 0 && args[0] == "check" {
+		if len(args) == 1 {
+			fmt.Fprintln(os.Stderr, "usage: dito check file.dito ...")
+			os.Exit(2)
+		}
+		setImportPath(args[1:], *importPath, os.Getenv("DITOPATH"))
+		os.Exit(checkFiles(args[1:], os.Stdout))
+	}
+	setImportPath(args[:min(len(args), 1)], *importPath, os.Getenv("DITOPATH"))
 	if len(args) > 0 {
 		filepath := args[0]
 		file, err := ioutil.ReadFile(filepath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		}
-		execFile(string(file), os.Stdout)
+		execFile(filepath, string(file), os.Stdout)
 		return
 	}
 	welcomeMsg(repl.QUIT)
 	repl.Start(os.Stdin, os.Stdout)
 }
 
-func execFile(file string, out io.Writer) {
+func execFile(filename, file string, out io.Writer) {
 	env := object.InitialEnvironment()
-	l := scanner.Init(file + "\n\nmain()")
+	l := scanner.InitFile(filename, file)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
 		p.PrintParseErrors(out, p.Errors())
 		return
 	}
-	evaluated := eval.Eval(program, env)
+	if errs := resolver.Resolve(program); resolver.HasErrors(errs) {
+		resolver.PrintErrors(out, errs)
+		return
+	} else if len(errs) != 0 {
+		resolver.PrintErrors(os.Stderr, errs)
+	}
+	var evaluated object.Object
+	if *useVM {
+		evaluated = runVM(program)
+	} else {
+		evaluated = runEval(program, env)
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		io.WriteString(out, err.Traceback())
+		return
+	}
 	if evaluated != nil && evaluated != object.NONE {
 		io.WriteString(out, evaluated.Inspect())
 		io.WriteString(out, "\n")
 	}
-	// quick hack for automatic main calls.
+}
+
+// definesMain : report whether the program defines main at the top level.
+func definesMain(program *ast.Program) bool {
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.Function:
+			if stmt.Name != nil && stmt.Name.Value == "main" {
+				return true
+			}
+		case *ast.AssignmentStatement:
+			if stmt.Name.Value == "main" {
+				return true
+			}
+		}
+	}
+	return false
+}
 
+// checkFiles : report the errors the resolver finds in each file without
+// running them, returning the exit status.
+func checkFiles(filenames []string, out io.Writer) int {
+	status := 0
+	for _, filename := range filenames {
+		file, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		p := parser.New(scanner.InitFile(filename, string(file)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			p.PrintParseErrors(out, p.Errors())
+			status = 1
+			continue
+		}
+		errs := resolver.Check(program)
+		if len(errs) != 0 {
+			resolver.PrintErrors(out, errs)
+		}
+		if resolver.HasErrors(errs) {
+			status = 1
+		}
+	}
+	return status
+}
+
+// runEval : evaluate the program in env then call its main function, when it
+// defines one, returning the value of main or of the last statement.
+func runEval(program *ast.Program, env *object.Environment) object.Object {
+	evaluated := eval.Eval(program, env)
+	if _, ok := evaluated.(*object.Error); ok || !definesMain(program) {
+		return evaluated
+	}
+	main, _ := env.Get("main")
+	return eval.ApplyFunction(main, []object.Object{}, env)
+}
+
+// runVM : compile the program to bytecode and execute it, then call its main
+// function like runEval.
+func runVM(program *ast.Program) object.Object {
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		return object.NewError("Compile error: %s", err)
+	}
+	machine := vm.New(c.Bytecode())
+	evaluated := machine.Run()
+	if _, ok := evaluated.(*object.Error); ok || !definesMain(program) {
+		return evaluated
+	}
+	return machine.Call("main")
+}
+
+// setImportPath : search the directories given on the command line then those
+// of the DITOPATH environment variable, then the lib directories next to the
+// scripts run and next to the dito executable, before the default path of
+// the runtime dito runs code with. So the standard library is found wherever
+// dito is run from.
+func setImportPath(scripts []string, lists ...string) {
+	var dirs []string
+	for _, list := range lists {
+		if list != "" {
+			dirs = append(dirs, filepath.SplitList(list)...)
+		}
+	}
+	for _, script := range scripts {
+		dirs = append(dirs, filepath.Join(filepath.Dir(script), "lib"))
+	}
+	if exe, err := os.Executable(); err == nil {
+		if exe, err = filepath.EvalSymlinks(exe); err == nil {
+			dirs = append(dirs, filepath.Join(filepath.Dir(exe), "lib"))
+		}
+	}
+	seen := make(map[string]bool)
+	path := []string{}
+	for _, dir := range append(dirs, object.DefaultRuntime.ImportPath...) {
+		if !seen[filepath.Clean(dir)] {
+			seen[filepath.Clean(dir)] = true
+			path = append(path, dir)
+		}
+	}
+	object.DefaultRuntime.ImportPath = path
 }
 
 func welcomeMsg(quit string) {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"dito/src/token"
 	"fmt"
+	"math/big"
 	"strings"
 )
 
@@ -11,8 +12,25 @@ import (
 type Node interface {
 	tokenLiteral() string
 	String() string
+	// Pos : position of the first character of the node.
+	Pos() token.Pos
+	// End : position of the first character immediately after the node.
+	End() token.Pos
 }
 
+// Span : the source positions a node covers. It is embedded in every node
+// and filled in by the parser.
+type Span struct {
+	Start token.Pos
+	Stop  token.Pos
+}
+
+// Pos : position of the first character of the node.
+func (s Span) Pos() token.Pos { return s.Start }
+
+// End : position of the first character immediately after the node.
+func (s Span) End() token.Pos { return s.Stop }
+
 // Statement : Expression |
 type Statement interface {
 	Node
@@ -44,6 +62,23 @@ func (p *Program) tokenLiteral() string {
 	}
 	return ""
 }
+
+// Pos : position of the first statement.
+func (p *Program) Pos() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Pos{}
+}
+
+// End : position immediately after the last statement.
+func (p *Program) End() token.Pos {
+	if n := len(p.Statements); n > 0 {
+		return p.Statements[n-1].End()
+	}
+	return token.Pos{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 	for _, s := range p.Statements {
@@ -60,6 +95,7 @@ Statements
 
 // BlockStatement : a group of one or more statments inside curly brackets.
 type BlockStatement struct {
+	Span
 	Token      token.Token // "{"
 	Statements []Statement
 }
@@ -76,6 +112,7 @@ func (bs *BlockStatement) String() string {
 
 // AssignmentStatement : let Identifier = expr | let mut Identifier = expr
 type AssignmentStatement struct {
+	Span
 	Token token.Token // either let or mut
 	Name  *Identifier
 	Value Expression
@@ -94,6 +131,7 @@ func (as *AssignmentStatement) String() string {
 // ReAssignStatement : Identifier assignOp expr
 // 	assignOp =  	= += -= *= /= %=
 type ReAssignStatement struct {
+	Span
 	Token token.Token // assignment operator.
 	Name  *Identifier
 	Value Expression
@@ -111,6 +149,7 @@ func (as *ReAssignStatement) String() string {
 
 // IndexAssignmentStatement is
 type IndexAssignmentStatement struct {
+	Span
 	Token  token.Token
 	IdxExp *IndexExpression
 	Value  Expression
@@ -126,8 +165,49 @@ func (ias *IndexAssignmentStatement) String() string {
 	return out.String()
 }
 
+// AttributeAssignmentStatement : AttrExp assignmentOperator Value
+type AttributeAssignmentStatement struct {
+	Span
+	Token   token.Token
+	AttrExp *AttributeExpression
+	Value   Expression
+}
+
+func (aas *AttributeAssignmentStatement) statementNode()       {}
+func (aas *AttributeAssignmentStatement) tokenLiteral() string { return aas.Token.String() }
+func (aas *AttributeAssignmentStatement) String() string {
+	return aas.AttrExp.String() + " " + aas.tokenLiteral() + " " + aas.Value.String()
+}
+
+// StructStatement : struct Name { Fields Methods }
+type StructStatement struct {
+	Span
+	Token   token.Token // 'struct'
+	Name    *Identifier
+	Fields  []*Identifier
+	Methods []*Function
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) tokenLiteral() string { return ss.Token.String() }
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+	out.WriteString(ss.tokenLiteral() + " " + ss.Name.String() + " {\n")
+	out.WriteString(strings.Join(fields, ", ") + "\n")
+	for _, m := range ss.Methods {
+		out.WriteString(m.String() + "\n")
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
 // ReturnStatement is this
 type ReturnStatement struct {
+	Span
 	Token token.Token // return
 	Value Expression
 }
@@ -143,6 +223,7 @@ func (rs *ReturnStatement) String() string {
 
 // ExpressionStatement :
 type ExpressionStatement struct {
+	Span
 	Token      token.Token
 	Expression Expression
 }
@@ -158,6 +239,7 @@ func (es *ExpressionStatement) String() string {
 
 // IfStatement :
 type IfStatement struct {
+	Span
 	Token       token.Token // 'if'
 	Condition   Expression
 	Consequence *BlockStatement
@@ -181,6 +263,7 @@ func (ie *IfStatement) String() string {
 
 // ForStatement :
 type ForStatement struct {
+	Span
 	Token     token.Token // 'for'
 	Condition Expression
 	ID        *Identifier
@@ -194,18 +277,120 @@ func (fs *ForStatement) String() string {
 	return "for statement"
 }
 
-// ImportStatement :
+// ImportStatement : import Value, import Value as Alias or
+// from Value import Names
 type ImportStatement struct {
-	Token token.Token // 'import'
+	Span
+	Token token.Token // 'import' or 'from'
 	Value string
+	Alias *Identifier   // nil unless renamed with 'as'.
+	Names []*Identifier // names imported by 'from'.
 }
 
 func (is *ImportStatement) statementNode()       {}
 func (is *ImportStatement) tokenLiteral() string { return is.Token.String() }
 func (is *ImportStatement) String() string {
-	return "Import statement"
+	if is.Token == token.FROM {
+		names := []string{}
+		for _, n := range is.Names {
+			names = append(names, n.String())
+		}
+		return "from " + is.Value + " import " + strings.Join(names, ", ")
+	}
+	if is.Alias != nil {
+		return "import " + is.Value + " as " + is.Alias.String()
+	}
+	return "import " + is.Value
+}
+
+// TryStatement : try { Body } catch Name { Handler }
+type TryStatement struct {
+	Span
+	Token   token.Token // 'try'
+	Body    *BlockStatement
+	Name    *Identifier // nil when the error is not bound.
+	Handler *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) tokenLiteral() string { return ts.Token.String() }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("try {\n")
+	out.WriteString(ts.Body.String())
+	out.WriteString("} catch ")
+	if ts.Name != nil {
+		out.WriteString(ts.Name.String() + " ")
+	}
+	out.WriteString("{\n")
+	out.WriteString(ts.Handler.String())
+	out.WriteString("}")
+	return out.String()
+}
+
+// RaiseStatement : raise expression
+type RaiseStatement struct {
+	Span
+	Token token.Token // 'raise'
+	Value Expression
+}
+
+func (rs *RaiseStatement) statementNode()       {}
+func (rs *RaiseStatement) tokenLiteral() string { return rs.Token.String() }
+func (rs *RaiseStatement) String() string {
+	return rs.tokenLiteral() + " " + rs.Value.String()
+}
+
+// YieldStatement : yield expression
+type YieldStatement struct {
+	Span
+	Token token.Token // 'yield'
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) tokenLiteral() string { return ys.Token.String() }
+func (ys *YieldStatement) String() string {
+	return ys.tokenLiteral() + " " + ys.Value.String()
+}
+
+// NonlocalStatement : nonlocal identifier, identifier...
+type NonlocalStatement struct {
+	Span
+	Token token.Token // 'nonlocal'
+	Names []*Identifier
+}
+
+func (ns *NonlocalStatement) statementNode()       {}
+func (ns *NonlocalStatement) tokenLiteral() string { return ns.Token.String() }
+func (ns *NonlocalStatement) String() string {
+	names := []string{}
+	for _, n := range ns.Names {
+		names = append(names, n.String())
+	}
+	return ns.tokenLiteral() + " " + strings.Join(names, ", ")
+}
+
+// BreakStatement : break
+type BreakStatement struct {
+	Span
+	Token token.Token // 'break'
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) tokenLiteral() string { return bs.Token.String() }
+func (bs *BreakStatement) String() string       { return bs.tokenLiteral() }
+
+// ContinueStatement : continue
+type ContinueStatement struct {
+	Span
+	Token token.Token // 'continue'
 }
 
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) tokenLiteral() string { return cs.Token.String() }
+func (cs *ContinueStatement) String() string       { return cs.tokenLiteral() }
+
 /*
 ######## Function Types
 */
@@ -215,19 +400,32 @@ func (is *ImportStatement) String() string {
 //     body
 // }
 type Function struct {
+	Span
 	Token      token.Token // token.DEF
 	Parameters []*Identifier
 	Name       *Identifier
+	ReturnType *Identifier // the annotated type of its return value, or nil.
 	Body       *BlockStatement
+	Generator  bool // the body contains a yield statement.
 }
 
 func (f *Function) statementNode()       {}
 func (f *Function) tokenLiteral() string { return f.Token.String() }
 func (f *Function) String() string       { return fmt.Sprintf("<%s>", f.Name.Value) }
 
+// ReturnTypeName : the name of the annotated return type, empty when the
+// function has none.
+func (f *Function) ReturnTypeName() string {
+	if f.ReturnType == nil {
+		return ""
+	}
+	return f.ReturnType.Value
+}
+
 // LambdaFunction : single expression function.
 // func(args) -> expr
 type LambdaFunction struct {
+	Span
 	Token      token.Token // token.DEF
 	Parameters []*Identifier
 	Expr       Expression
@@ -239,6 +437,7 @@ func (lf *LambdaFunction) String() string       { return "<LambdaFunction>" }
 
 // CallExpression :
 type CallExpression struct {
+	Span
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
@@ -266,6 +465,7 @@ func (ce *CallExpression) String() string {
 
 // IndexExpression is
 type IndexExpression struct {
+	Span
 	Token token.Token // [
 	Left  Expression
 	Index Expression
@@ -277,8 +477,23 @@ func (ie *IndexExpression) String() string {
 	return fmt.Sprintf("(%s[%s])", ie.Left.String(), ie.Index.String())
 }
 
+// AttributeExpression : Left '.' Name
+type AttributeExpression struct {
+	Span
+	Token token.Token // .
+	Left  Expression
+	Name  *Identifier
+}
+
+func (ae *AttributeExpression) expressionNode()      {}
+func (ae *AttributeExpression) tokenLiteral() string { return ae.Token.String() }
+func (ae *AttributeExpression) String() string {
+	return ae.Left.String() + "." + ae.Name.String()
+}
+
 // SliceExpression :
 type SliceExpression struct {
+	Span
 	Token token.Token // :
 	Left  Expression
 	S     Expression
@@ -293,6 +508,7 @@ func (se *SliceExpression) String() string {
 
 // PrefixExpression :
 type PrefixExpression struct {
+	Span
 	Token    token.Token
 	Operator string
 	Right    Expression
@@ -311,6 +527,7 @@ func (pe *PrefixExpression) String() string {
 
 // InfixExpression :
 type InfixExpression struct {
+	Span
 	Token    token.Token
 	Left     Expression
 	Operator string
@@ -332,6 +549,7 @@ func (ie *InfixExpression) String() string {
 
 // IfElseExpression :
 type IfElseExpression struct {
+	Span
 	Token       token.Token // 'if'
 	Initial     Expression
 	Condition   Expression
@@ -357,6 +575,7 @@ func (ie *IfElseExpression) String() string {
 
 // DictLiteral : Key value pairs.
 type DictLiteral struct {
+	Span
 	Token token.Token
 	Items map[Expression]Expression
 }
@@ -378,6 +597,7 @@ func (dl *DictLiteral) String() string {
 
 // ArrayLiteral : Arrays can contain an assorted range of elements.
 type ArrayLiteral struct {
+	Span
 	Token    token.Token
 	Elements []Expression
 }
@@ -402,16 +622,28 @@ func (al *ArrayLiteral) String() string {
 
 // Identifier : alphanumeric variable name.
 type Identifier struct {
+	Span
 	Token token.Token // token.IDVAL
 	Value string
+	Type  *Identifier // the annotated type of a parameter or let, or nil.
 }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) tokenLiteral() string { return i.Token.String() }
 func (i *Identifier) String() string       { return i.Value }
 
+// TypeName : the name of the type the identifier is annotated with, empty
+// when it has none.
+func (i *Identifier) TypeName() string {
+	if i == nil || i.Type == nil {
+		return ""
+	}
+	return i.Type.Value
+}
+
 // StringLiteral :
 type StringLiteral struct {
+	Span
 	Token token.Token
 	Value string
 }
@@ -420,11 +652,25 @@ func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) tokenLiteral() string { return sl.Token.String() }
 func (sl *StringLiteral) String() string       { return sl.Value }
 
-// IntegerLiteral :  any non decimal numeric constant between
+// CharLiteral : a single character between single quotes.
+type CharLiteral struct {
+	Span
+	Token   token.Token
+	Literal string
+	Value   rune
+}
+
+func (cl *CharLiteral) expressionNode()      {}
+func (cl *CharLiteral) tokenLiteral() string { return cl.Literal }
+func (cl *CharLiteral) String() string       { return cl.Literal }
+
+// IntegerLiteral :  any non decimal numeric constant
 type IntegerLiteral struct {
+	Span
 	Token   token.Token // token.INT
 	Literal string      // int as a string repr
 	Value   int         // int as a int64
+	Big     *big.Int    // when too large for Value
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
@@ -433,6 +679,7 @@ func (il *IntegerLiteral) String() string       { return il.Literal }
 
 // FloatLiteral :
 type FloatLiteral struct {
+	Span
 	Token   token.Token
 	Literal string
 	Value   float64
@@ -444,6 +691,7 @@ func (fl *FloatLiteral) String() string       { return fl.Literal }
 
 // BooleanLiteral :
 type BooleanLiteral struct {
+	Span
 	Token token.Token
 	// no literal because Token.String() will be true or false
 	Value bool
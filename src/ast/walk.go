@@ -0,0 +1,122 @@
+package ast
+
+// Inspect : traverse the tree rooted at node in depth first order calling f
+// for each node. The children of a node are only visited when f returns true
+// for it. Optional parts of a node which are not present are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	switch n := node.(type) {
+	case *Program:
+		inspectStatements(n.Statements, f)
+	case *BlockStatement:
+		inspectStatements(n.Statements, f)
+	case *AssignmentStatement:
+		Inspect(n.Name, f)
+		inspectExpressions(f, n.Value)
+	case *ReAssignStatement:
+		Inspect(n.Name, f)
+		inspectExpressions(f, n.Value)
+	case *IndexAssignmentStatement:
+		Inspect(n.IdxExp, f)
+		inspectExpressions(f, n.Value)
+	case *AttributeAssignmentStatement:
+		Inspect(n.AttrExp, f)
+		inspectExpressions(f, n.Value)
+	case *StructStatement:
+		Inspect(n.Name, f)
+		for _, field := range n.Fields {
+			Inspect(field, f)
+		}
+		for _, m := range n.Methods {
+			Inspect(m, f)
+		}
+	case *ReturnStatement:
+		inspectExpressions(f, n.Value)
+	case *YieldStatement:
+		inspectExpressions(f, n.Value)
+	case *RaiseStatement:
+		inspectExpressions(f, n.Value)
+	case *NonlocalStatement:
+		for _, name := range n.Names {
+			Inspect(name, f)
+		}
+	case *ExpressionStatement:
+		inspectExpressions(f, n.Expression)
+	case *IfStatement:
+		inspectExpressions(f, n.Condition)
+		Inspect(n.Consequence, f)
+		if n.Alternative != nil {
+			Inspect(n.Alternative, f)
+		}
+	case *ForStatement:
+		if n.ID != nil {
+			Inspect(n.ID, f)
+		}
+		inspectExpressions(f, n.Condition, n.Iter)
+		Inspect(n.LoopBody, f)
+	case *ImportStatement:
+		if n.Alias != nil {
+			Inspect(n.Alias, f)
+		}
+		for _, name := range n.Names {
+			Inspect(name, f)
+		}
+	case *TryStatement:
+		Inspect(n.Body, f)
+		if n.Name != nil {
+			Inspect(n.Name, f)
+		}
+		Inspect(n.Handler, f)
+	case *Function:
+		Inspect(n.Name, f)
+		for _, p := range n.Parameters {
+			Inspect(p, f)
+		}
+		Inspect(n.Body, f)
+	case *LambdaFunction:
+		for _, p := range n.Parameters {
+			Inspect(p, f)
+		}
+		inspectExpressions(f, n.Expr)
+	case *CallExpression:
+		inspectExpressions(f, n.Function)
+		inspectExpressions(f, n.Arguments...)
+	case *IndexExpression:
+		inspectExpressions(f, n.Left, n.Index)
+	case *AttributeExpression:
+		inspectExpressions(f, n.Left)
+		Inspect(n.Name, f)
+	case *SliceExpression:
+		inspectExpressions(f, n.Left, n.S, n.E)
+	case *PrefixExpression:
+		inspectExpressions(f, n.Right)
+	case *InfixExpression:
+		inspectExpressions(f, n.Left, n.Right)
+	case *IfElseExpression:
+		inspectExpressions(f, n.Initial, n.Condition, n.Alternative)
+	case *ArrayLiteral:
+		inspectExpressions(f, n.Elements...)
+	case *DictLiteral:
+		for key, val := range n.Items {
+			inspectExpressions(f, key, val)
+		}
+	}
+}
+
+func inspectStatements(stmts []Statement, f func(Node) bool) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			Inspect(stmt, f)
+		}
+	}
+}
+
+func inspectExpressions(f func(Node) bool, exprs ...Expression) {
+	for _, expr := range exprs {
+		if expr != nil {
+			Inspect(expr, f)
+		}
+	}
+}
@@ -0,0 +1,1062 @@
+/*Package compiler lowers a Dito ast.Program into bytecode for the vm package.
+Identifiers are resolved at compile time into global, local or free variable
+slots so the vm never has to search through chained environments.
+*/
+package compiler
+
+import (
+	"dito/src/ast"
+	"dito/src/eval"
+	"dito/src/object"
+	"dito/src/token"
+	"errors"
+	"fmt"
+)
+
+// Module : an imported file. Init runs its top level code, after which the
+// vm looks up the attributes of the module in its global Slots.
+type Module struct {
+	Name  string
+	Path  string
+	Slots map[string]int
+	Init  *object.CompiledFunction
+}
+
+// Type : return objects type as a TypeFlag
+func (m *Module) Type() object.TypeFlag { return object.ModuleType }
+
+// Inspect : return a string representation of the objects value.
+func (m *Module) Inspect() string { return fmt.Sprintf("<module %s>", m.Name) }
+
+// ConvertType : return the conversion into the specified type
+func (m *Module) ConvertType(which object.TypeFlag) object.Object {
+	return object.NewError(object.ConvertTypeError, m.Type(), which)
+}
+
+// Bytecode : the output of the compiler. Globals names each global slot so
+// the vm can report reads of names that were never set. NumLocals is the
+// number of slots the main frame needs for variables of top level blocks.
+// Positions maps the Instructions back to the source for tracebacks.
+type Bytecode struct {
+	Instructions Instructions
+	Constants    []object.Object
+	Globals      []string
+	NumLocals    int
+	Positions    []object.Position
+}
+
+// compilationScope : instructions emitted for the function body currently
+// being compiled.
+type compilationScope struct {
+	instructions Instructions
+	positions    []object.Position // see object.CompiledFunction.PosAt.
+	loops        []*loop           // loops enclosing the current statement.
+	tries        int               // try statements enclosing the current statement.
+	generator    bool              // the function being compiled contains yield.
+	returnT      string            // the annotated return type of the function.
+}
+
+// loop : where break and continue statements in a loop jump to. Breaks are
+// patched to the loops exit once it has been compiled.
+type loop struct {
+	start  int
+	breaks []int
+	tries  int    // try statements open when the loop started.
+	iter   bool   // for in loops keep their iterator on the stack.
+	result Symbol // holds the value of the last iteration, see enterLoop.
+}
+
+// loopResult : the name of the hidden variable holding the value of a loop.
+const loopResult = "<loop>"
+
+// Compiler : walks an ast emitting instructions into the current scope.
+type Compiler struct {
+	constants   []object.Object
+	builtins    map[string]int
+	modules     map[string]int // constant index of each compiled module by path.
+	importing   []string       // paths of the modules being compiled.
+	symbolTable *SymbolTable
+	scopes      []compilationScope
+	scopeIndex  int
+	pos         token.Pos       // of the node the instructions emitted come from.
+	runtime     *object.Runtime // the program will run in, deciding what it may import.
+}
+
+// New : return a compiler ready to compile a program run by DefaultRuntime.
+func New() *Compiler {
+	return NewWithRuntime(object.DefaultRuntime)
+}
+
+// NewWithRuntime : return a compiler ready to compile a program run by rt.
+func NewWithRuntime(rt *object.Runtime) *Compiler {
+	return &Compiler{
+		runtime:     rt,
+		constants:   []object.Object{},
+		builtins:    make(map[string]int),
+		modules:     make(map[string]int),
+		symbolTable: NewSymbolTable(),
+		scopes:      []compilationScope{{instructions: Instructions{}}},
+	}
+}
+
+// Bytecode : return the compiled program.
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+		Globals:      *c.globalTable().globals,
+		NumLocals:    c.globalTable().NumLocals(),
+		Positions:    c.scopes[c.scopeIndex].positions,
+	}
+}
+
+// Compile : compile an ast node into the current scope. Every statement
+// leaves exactly one value on the stack, like every call to eval.Eval
+// returns one object.
+func (c *Compiler) Compile(node ast.Node) error {
+	defer c.at(node)()
+	switch node := node.(type) {
+	case *ast.Program:
+		return c.compileProgram(node)
+
+	// Statements
+	case *ast.AssignmentStatement:
+		return c.compileAssignment(node)
+	case *ast.ReAssignStatement:
+		return c.compileReAssign(node)
+	case *ast.IndexAssignmentStatement:
+		return c.compileIndexAssignment(node)
+	case *ast.AttributeAssignmentStatement:
+		return c.compileAttributeAssignment(node)
+	case *ast.StructStatement:
+		return c.compileStructStatement(node)
+	case *ast.ReturnStatement:
+		if err := c.compileTail(node.Value); err != nil {
+			return err
+		}
+		c.emit(OpReturnValue)
+	case *ast.YieldStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		// the generator resumes after OpYield, with None as the statements value.
+		c.emit(OpYield)
+		c.emit(OpNone)
+	case *ast.ExpressionStatement:
+		return c.Compile(node.Expression)
+	case *ast.IfStatement:
+		return c.compileIfStatement(node)
+	case *ast.ForStatement:
+		if node.Token == token.IN {
+			return c.compileForIn(node)
+		}
+		return c.compileForWhile(node)
+	case *ast.BlockStatement:
+		return c.compileBlock(node.Statements)
+	case *ast.ImportStatement:
+		return c.compileImport(node)
+	case *ast.TryStatement:
+		return c.compileTryStatement(node)
+	case *ast.RaiseStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(OpRaise)
+	case *ast.NonlocalStatement:
+		for _, name := range node.Names {
+			if !c.symbolTable.InFunction() {
+				return fmt.Errorf("'nonlocal' outside function")
+			}
+			if !c.symbolTable.Nonlocal(name.Value) {
+				return fmt.Errorf("no binding for nonlocal '%s' found", name.Value)
+			}
+		}
+		c.emit(OpNone)
+	case *ast.BreakStatement:
+		return c.compileLoopControl(true)
+	case *ast.ContinueStatement:
+		return c.compileLoopControl(false)
+
+	// Expressions
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(node)
+	case *ast.InfixExpression:
+		return c.compileInfixExpression(node)
+	case *ast.IfElseExpression:
+		return c.compileIfElseExpression(node, false)
+	case *ast.IndexExpression:
+		if err := c.compileAll(node.Left, node.Index); err != nil {
+			return err
+		}
+		c.emit(OpIndex)
+	case *ast.AttributeExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		c.emit(OpGetAttr, c.addConstant(object.NewString(node.Name.Value)))
+	case *ast.SliceExpression:
+		if err := c.compileAll(node.Left, node.S, node.E); err != nil {
+			return err
+		}
+		c.emit(OpSlice)
+
+	// Functions
+	case *ast.Function:
+		return c.compileFunctionStatement(node)
+	case *ast.LambdaFunction:
+		return c.compileFunction("", node.Parameters, "", node.Expr, true, false)
+	case *ast.CallExpression:
+		return c.compileCall(node, OpCall)
+
+	// Atoms
+	case *ast.Identifier:
+		return c.compileIdentifier(node)
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewString(node.Value)))
+	case *ast.CharLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewChar(node.Value)))
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			c.emit(OpConstant, c.addConstant(object.NewBigInt(node.Big)))
+		} else {
+			c.emit(OpConstant, c.addConstant(object.NewInt(node.Value)))
+		}
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewFloat(node.Value)))
+	case *ast.BooleanLiteral:
+		if node.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+
+	// Composite
+	case *ast.ArrayLiteral:
+		if err := c.compileAll(node.Elements...); err != nil {
+			return err
+		}
+		c.emit(OpArray, len(node.Elements))
+	case *ast.DictLiteral:
+		for key, val := range node.Items {
+			if err := c.compileAll(key, val); err != nil {
+				return err
+			}
+		}
+		c.emit(OpDict, len(node.Items))
+
+	default:
+		return fmt.Errorf("compiler: unsupported node %T", node)
+	}
+	return nil
+}
+
+func (c *Compiler) compileAll(nodes ...ast.Expression) error {
+	for _, n := range nodes {
+		if err := c.Compile(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileProgram : top level statements are compiled like a block, except
+// each value is popped so the vm can report the last one as the result.
+func (c *Compiler) compileProgram(program *ast.Program) error {
+	c.declareGlobals(program.Statements)
+	c.declareCells(program)
+	for _, stmt := range program.Statements {
+		if err := c.Compile(stmt); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+	}
+	return nil
+}
+
+// declareGlobals : give every top level name a slot up front so function
+// bodies can refer to globals that are defined further down the file.
+func (c *Compiler) declareGlobals(stmts []ast.Statement) {
+	if c.symbolTable.Outer != nil {
+		return
+	}
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.AssignmentStatement:
+			c.symbolTable.Define(stmt.Name.Value, stmt.Token != token.LET)
+			c.symbolTable.Annotate(stmt.Name.Value, stmt.Name.TypeName())
+		case *ast.Function:
+			c.symbolTable.Define(stmt.Name.Value, false)
+		case *ast.StructStatement:
+			c.symbolTable.Define(stmt.Name.Value, false)
+		}
+	}
+}
+
+// declareCells : keep the variables of the current function, or the top
+// level blocks of a program, which nested functions may refer to in cells.
+// A closure captures the cell instead of the value of the variable, so that
+// assignments made by either side are seen by the other.
+func (c *Compiler) declareCells(body ast.Node) {
+	frame := c.symbolTable.frame()
+	if frame.cells == nil {
+		frame.cells = make(map[string]bool)
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.Function, *ast.LambdaFunction:
+			ast.Inspect(n, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Identifier); ok {
+					frame.cells[id.Value] = true
+				}
+				return true
+			})
+			return false
+		}
+		return true
+	})
+}
+
+func (c *Compiler) compileBlock(stmts []ast.Statement) error {
+	if len(stmts) == 0 {
+		c.emit(OpNone)
+		return nil
+	}
+	for i, stmt := range stmts {
+		if err := c.Compile(stmt); err != nil {
+			return err
+		}
+		if i < len(stmts)-1 {
+			c.emit(OpPop)
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileIdentifier(node *ast.Identifier) error {
+	if sym, ok := c.symbolTable.Resolve(node.Value); ok {
+		c.loadSymbol(sym)
+		return nil
+	}
+	if val, ok := object.SystemVars[node.Value]; ok {
+		c.emit(OpConstant, c.addConstant(val))
+		return nil
+	}
+	if builtin, ok := eval.Builtins[node.Value]; ok {
+		idx, ok := c.builtins[node.Value]
+		if !ok {
+			idx = c.addConstant(builtin)
+			c.builtins[node.Value] = idx
+		}
+		c.emit(OpConstant, idx)
+		return nil
+	}
+	// an unknown name may still be set as a global before this code runs,
+	// for example by a file which imports the current one.
+	c.loadSymbol(c.globalTable().Define(node.Value, false))
+	return nil
+}
+
+func (c *Compiler) globalTable() *SymbolTable {
+	s := c.symbolTable
+	for s.Outer != nil {
+		s = s.Outer
+	}
+	return s
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch {
+	case s.Scope == GlobalScope:
+		c.emit(OpGetGlobal, s.Index)
+	case s.Scope == LocalScope && s.Cell:
+		c.emit(OpGetCell, s.Index)
+	case s.Scope == LocalScope:
+		c.emit(OpGetLocal, s.Index)
+	case s.Scope == FreeScope && s.Cell:
+		c.emit(OpGetFreeCell, s.Index)
+	case s.Scope == FreeScope:
+		c.emit(OpGetFree, s.Index)
+	case s.Scope == FunctionScope:
+		c.emit(OpCurrentClosure)
+	}
+}
+
+// loadCapture : push what a closure captures for a free variable, which is
+// the cell of the variable rather than its value.
+func (c *Compiler) loadCapture(s Symbol) {
+	switch s.Scope {
+	case LocalScope:
+		c.emit(OpGetLocal, s.Index)
+	case FreeScope:
+		c.emit(OpGetFree, s.Index)
+	default:
+		c.loadSymbol(s)
+	}
+}
+
+// storeSymbol : assign the value on top of the stack to a variable.
+func (c *Compiler) storeSymbol(s Symbol) {
+	switch {
+	case s.Scope == GlobalScope:
+		c.emit(OpSetGlobal, s.Index)
+	case s.Scope == FreeScope:
+		c.emit(OpSetFreeCell, s.Index)
+	case s.Cell:
+		c.emit(OpSetCell, s.Index)
+	default:
+		c.emit(OpSetLocal, s.Index)
+	}
+}
+
+// define : declare name in the current scope, returning its symbol and the
+// instruction which binds the value on top of the stack to it. A captured
+// variable gets a new cell each time its declaration runs, so closures made
+// in different iterations of a loop each capture their own.
+func (c *Compiler) define(name string, mutable bool) (Symbol, Opcode, error) {
+	if !c.symbolTable.Declare(name) {
+		return Symbol{}, 0, fmt.Errorf("identifier '%s' is already declared in this scope", name)
+	}
+	sym := c.symbolTable.Define(name, mutable)
+	switch {
+	case sym.Scope == GlobalScope:
+		return sym, OpSetGlobal, nil
+	case !sym.Cell:
+		return sym, OpSetLocal, nil
+	}
+	return sym, OpNewCell, nil
+}
+
+// defineAndStore : define name, binding the value on top of the stack to it.
+func (c *Compiler) defineAndStore(name string, mutable bool) error {
+	sym, op, err := c.define(name, mutable)
+	if err != nil {
+		return err
+	}
+	c.emit(op, sym.Index)
+	return nil
+}
+
+func (c *Compiler) compileAssignment(node *ast.AssignmentStatement) error {
+	mutable := node.Token != token.LET
+	if lambda, ok := node.Value.(*ast.LambdaFunction); ok {
+		// define the name first so the lambda can call itself.
+		sym, op, err := c.define(node.Name.Value, mutable)
+		if err != nil {
+			return err
+		}
+		sym = c.symbolTable.Annotate(sym.Name, node.Name.TypeName())
+		if err := c.compileFunction(node.Name.Value, lambda.Parameters, "", lambda.Expr, true, false); err != nil {
+			return err
+		}
+		c.checkType(sym)
+		c.emit(op, sym.Index)
+		c.emit(OpNone)
+		return nil
+	}
+	if err := c.Compile(node.Value); err != nil {
+		return err
+	}
+	sym, op, err := c.define(node.Name.Value, mutable)
+	if err != nil {
+		return err
+	}
+	sym = c.symbolTable.Annotate(sym.Name, node.Name.TypeName())
+	c.checkType(sym)
+	c.emit(op, sym.Index)
+	c.emit(OpNone)
+	return nil
+}
+
+// checkType : check the value on top of the stack has the annotated type of
+// the symbol it is assigned to.
+func (c *Compiler) checkType(sym Symbol) {
+	if sym.Type != "" {
+		c.emit(OpCheckType, c.addConstant(object.NewString(sym.Type)), c.addConstant(object.NewString(sym.Name)))
+	}
+}
+
+func (c *Compiler) compileReAssign(node *ast.ReAssignStatement) error {
+	name := node.Name.Value
+	sym, ok := c.symbolTable.Resolve(name)
+	if !ok {
+		if _, ok := object.SystemVars[name]; ok {
+			return fmt.Errorf("identifier '%s' has a immutable value", name)
+		}
+		return fmt.Errorf("identifier not found: '%s'", name)
+	}
+	if !sym.Mutable {
+		return fmt.Errorf("identifier '%s' has a immutable value", name)
+	}
+	outer := sym.Scope == FreeScope || (sym.Scope == GlobalScope && c.symbolTable.InFunction())
+	if outer && !c.symbolTable.IsNonlocal(name) {
+		return fmt.Errorf("cannot assign to '%s' of an enclosing scope, declare it nonlocal", name)
+	}
+	if sym.Scope == FreeScope && !sym.Cell {
+		return fmt.Errorf("cannot assign to '%s' of an enclosing scope", name)
+	}
+	if node.Token != token.ASSIGN {
+		opString := node.Token.String()
+		op, ok := binaryOperator(opString[:len(opString)-1])
+		if !ok {
+			return fmt.Errorf("unknown inplace binary op: '%s'", opString)
+		}
+		c.loadSymbol(sym)
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(OpBinary, op)
+	} else if err := c.Compile(node.Value); err != nil {
+		return err
+	}
+	c.checkType(sym)
+	c.storeSymbol(sym)
+	c.emit(OpNone)
+	return nil
+}
+
+func (c *Compiler) compileIndexAssignment(node *ast.IndexAssignmentStatement) error {
+	if err := c.compileAll(node.IdxExp.Left, node.IdxExp.Index, node.Value); err != nil {
+		return err
+	}
+	if node.Token == token.ASSIGN {
+		c.emit(OpSetIndex, 0)
+	} else {
+		opString := node.Token.String()
+		op, ok := binaryOperator(opString[:len(opString)-1])
+		if !ok {
+			return fmt.Errorf("unknown in place binary op: '%s'", opString)
+		}
+		c.emit(OpSetIndex, op+1)
+	}
+	c.emit(OpNone)
+	return nil
+}
+
+func (c *Compiler) compileAttributeAssignment(node *ast.AttributeAssignmentStatement) error {
+	if err := c.compileAll(node.AttrExp.Left, node.Value); err != nil {
+		return err
+	}
+	name := c.addConstant(object.NewString(node.AttrExp.Name.Value))
+	if node.Token == token.ASSIGN {
+		c.emit(OpSetAttr, name, 0)
+	} else {
+		opString := node.Token.String()
+		op, ok := binaryOperator(opString[:len(opString)-1])
+		if !ok {
+			return fmt.Errorf("unknown in place binary op: '%s'", opString)
+		}
+		c.emit(OpSetAttr, name, op+1)
+	}
+	c.emit(OpNone)
+	return nil
+}
+
+// compileStructStatement : the methods closures are created at runtime and
+// given to a copy of the constant Struct, just as literals build a Dict.
+func (c *Compiler) compileStructStatement(node *ast.StructStatement) error {
+	fields, fieldErr := eval.StructFields(node)
+	if fieldErr != nil {
+		return errors.New(fieldErr.Message)
+	}
+	sym, op, err := c.define(node.Name.Value, false)
+	if err != nil {
+		return err
+	}
+	for _, m := range node.Methods {
+		c.emit(OpConstant, c.addConstant(object.NewString(m.Name.Value)))
+		if err := c.compileFunction("", m.Parameters, m.ReturnTypeName(), m.Body, false, m.Generator); err != nil {
+			return err
+		}
+		// named once compiled, as a method is not in scope in its own body.
+		c.constants[len(c.constants)-1].(*object.CompiledFunction).Name = m.Name.Value
+	}
+	st := object.NewStruct(node.Name.Value, fields)
+	c.emit(OpStruct, c.addConstant(st), len(node.Methods))
+	c.emit(op, sym.Index)
+	c.loadSymbol(sym)
+	return nil
+}
+
+func (c *Compiler) compilePrefixExpression(node *ast.PrefixExpression) error {
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+	switch node.Operator {
+	case "not":
+		c.emit(OpNot)
+	case "-":
+		c.emit(OpMinus)
+	default:
+		return fmt.Errorf("unknown operator: %s", node.Operator)
+	}
+	return nil
+}
+
+func (c *Compiler) compileInfixExpression(node *ast.InfixExpression) error {
+	if node.Operator == "and" || node.Operator == "or" {
+		return c.compileLogical(node)
+	}
+	op, ok := binaryOperator(node.Operator)
+	if !ok {
+		return fmt.Errorf("unknown binary operation: '%s'", node.Operator)
+	}
+	if err := c.compileAll(node.Left, node.Right); err != nil {
+		return err
+	}
+	c.emit(OpBinary, op)
+	return nil
+}
+
+// compileLogical : compile an and or or expression, jumping over the right
+// operand when the left one decides its value.
+func (c *Compiler) compileLogical(node *ast.InfixExpression) error {
+	if err := c.Compile(node.Left); err != nil {
+		return err
+	}
+	op := OpJumpNotTrueOrPop
+	if node.Operator == "or" {
+		op = OpJumpTrueOrPop
+	}
+	jump := c.emit(op, 0)
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+func (c *Compiler) compileCall(node *ast.CallExpression, op Opcode) error {
+	if err := c.Compile(node.Function); err != nil {
+		return err
+	}
+	if err := c.compileAll(node.Arguments...); err != nil {
+		return err
+	}
+	c.emit(op, len(node.Arguments))
+	return nil
+}
+
+// compileTail : compile an expression the current function returns the value
+// of. Calls in tail position reuse the frame of the function, except inside a
+// try so that it can still catch their errors, a generator whose frame is
+// needed until it returns, or a function whose return type is checked.
+func (c *Compiler) compileTail(node ast.Expression) error {
+	defer c.at(node)()
+	scope := c.scopes[c.scopeIndex]
+	if c.scopeIndex == 0 || scope.tries > 0 || scope.generator || scope.returnT != "" {
+		return c.Compile(node)
+	}
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		return c.compileCall(node, OpTailCall)
+	case *ast.IfElseExpression:
+		if node.Alternative != nil {
+			return c.compileIfElseExpression(node, true)
+		}
+	}
+	return c.Compile(node)
+}
+
+func (c *Compiler) compileIfElseExpression(node *ast.IfElseExpression, tail bool) error {
+	branch := c.Compile
+	if tail {
+		branch = func(n ast.Node) error { return c.compileTail(n.(ast.Expression)) }
+	}
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+	jumpNotTrue := c.emit(OpJumpNotTrue, 0)
+	if err := branch(node.Initial); err != nil {
+		return err
+	}
+	jump := c.emit(OpJump, 0)
+	c.changeOperand(jumpNotTrue, len(c.currentInstructions()))
+	if err := branch(node.Alternative); err != nil {
+		return err
+	}
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+func (c *Compiler) compileIfStatement(node *ast.IfStatement) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+	jumpNotTrue := c.emit(OpJumpNotTrue, 0)
+	if err := c.compileScoped(node.Consequence); err != nil {
+		return err
+	}
+	jump := c.emit(OpJump, 0)
+	c.changeOperand(jumpNotTrue, len(c.currentInstructions()))
+	if node.Alternative != nil {
+		if err := c.compileScoped(node.Alternative); err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpNone)
+	}
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+func (c *Compiler) compileForWhile(node *ast.ForStatement) error {
+	c.enterBlock()
+	defer c.leaveBlock()
+	result := c.defineLoopResult()
+	start := len(c.currentInstructions())
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(OpJumpNotTrue, 0)
+	l := c.enterLoop(start, false, result)
+	if err := c.compileScoped(node.LoopBody); err != nil {
+		return err
+	}
+	c.storeSymbol(result)
+	c.emit(OpJump, start)
+	c.changeOperand(exit, len(c.currentInstructions()))
+	c.leaveLoop(l)
+	return nil
+}
+
+func (c *Compiler) compileForIn(node *ast.ForStatement) error {
+	c.enterBlock()
+	defer c.leaveBlock()
+	result := c.defineLoopResult()
+	if err := c.Compile(node.Iter); err != nil {
+		return err
+	}
+	c.emit(OpGetIter)
+	start := len(c.currentInstructions())
+	exit := c.emit(OpIterNext, 0)
+	// the loop variable belongs to the scope of the body, which is new for
+	// each iteration.
+	c.enterBlock()
+	err := c.defineAndStore(node.ID.Value, false)
+	l := c.enterLoop(start, true, result)
+	if err == nil {
+		err = c.Compile(node.LoopBody)
+	}
+	c.leaveBlock()
+	if err != nil {
+		return err
+	}
+	c.storeSymbol(result)
+	c.emit(OpJump, start)
+	c.changeOperand(exit, len(c.currentInstructions()))
+	c.leaveLoop(l)
+	return nil
+}
+
+// defineLoopResult : give the value of the loop about to be compiled a
+// variable in the current block, starting as None.
+func (c *Compiler) defineLoopResult() Symbol {
+	result := c.symbolTable.Define(loopResult, true)
+	c.emit(OpNone)
+	c.storeSymbol(result)
+	return result
+}
+
+// enterLoop : the value of a loop is that of the body in its last iteration,
+// like eval, so each iteration stores it in result. It is None when the last
+// iteration was left by break or continue.
+func (c *Compiler) enterLoop(start int, iter bool, result Symbol) *loop {
+	scope := &c.scopes[c.scopeIndex]
+	l := &loop{start: start, tries: scope.tries, iter: iter, result: result}
+	scope.loops = append(scope.loops, l)
+	return l
+}
+
+// leaveLoop : patch the loops breaks to jump to the current position, where
+// the value of the loop is pushed.
+func (c *Compiler) leaveLoop(l *loop) {
+	scope := &c.scopes[c.scopeIndex]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	for _, pos := range l.breaks {
+		c.changeOperand(pos, len(c.currentInstructions()))
+	}
+	c.loadSymbol(l.result)
+}
+
+// compileLoopControl : jump out of or back to the start of the innermost
+// loop, closing any try statements opened inside of it on the way.
+func (c *Compiler) compileLoopControl(isBreak bool) error {
+	scope := &c.scopes[c.scopeIndex]
+	if len(scope.loops) == 0 {
+		if isBreak {
+			return fmt.Errorf("'break' outside loop")
+		}
+		return fmt.Errorf("'continue' outside loop")
+	}
+	l := scope.loops[len(scope.loops)-1]
+	for i := l.tries; i < scope.tries; i++ {
+		c.emit(OpEndTry)
+	}
+	c.emit(OpNone)
+	c.storeSymbol(l.result)
+	if !isBreak {
+		c.emit(OpJump, l.start)
+		return nil
+	}
+	if l.iter {
+		c.emit(OpPop)
+	}
+	l.breaks = append(l.breaks, c.emit(OpJump, 0))
+	return nil
+}
+
+// compileTryStatement : when the body raises the vm unwinds the stack to
+// where it was at OpTry and jumps to the handler with the Exception pushed.
+func (c *Compiler) compileTryStatement(node *ast.TryStatement) error {
+	handler := c.emit(OpTry, 0)
+	c.scopes[c.scopeIndex].tries++
+	if err := c.compileScoped(node.Body); err != nil {
+		return err
+	}
+	c.scopes[c.scopeIndex].tries--
+	c.emit(OpEndTry)
+	jump := c.emit(OpJump, 0)
+	c.changeOperand(handler, len(c.currentInstructions()))
+	c.enterBlock()
+	var err error
+	if node.Name != nil {
+		err = c.defineAndStore(node.Name.Value, false)
+	} else {
+		c.emit(OpPop)
+	}
+	if err == nil {
+		err = c.Compile(node.Handler)
+	}
+	c.leaveBlock()
+	if err != nil {
+		return err
+	}
+	c.changeOperand(jump, len(c.currentInstructions()))
+	return nil
+}
+
+func (c *Compiler) compileFunctionStatement(node *ast.Function) error {
+	sym, op, err := c.define(node.Name.Value, false)
+	if err != nil {
+		return err
+	}
+	if err := c.compileFunction(node.Name.Value, node.Parameters, node.ReturnTypeName(), node.Body, false, node.Generator); err != nil {
+		return err
+	}
+	c.emit(op, sym.Index)
+	c.loadSymbol(sym)
+	return nil
+}
+
+// compileFunction : compile a function body in its own scope, then emit the
+// closure which captures the free variables it uses.
+func (c *Compiler) compileFunction(name string, params []*ast.Identifier, returnT string, body ast.Node, lambda, generator bool) error {
+	c.enterScope()
+	c.scopes[c.scopeIndex].generator = generator
+	c.scopes[c.scopeIndex].returnT = returnT
+	if name != "" {
+		c.symbolTable.DefineFunctionName(name)
+	}
+	c.declareCells(body)
+	for _, p := range params {
+		c.symbolTable.Declare(p.Value)
+		if sym := c.symbolTable.Define(p.Value, false); sym.Cell {
+			c.emit(OpGetLocal, sym.Index)
+			c.emit(OpNewCell, sym.Index)
+		}
+	}
+	compile := c.Compile
+	if lambda {
+		compile = func(n ast.Node) error { return c.compileTail(n.(ast.Expression)) }
+	}
+	if err := compile(body); err != nil {
+		return err
+	}
+	c.emit(OpReturnValue)
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.NumLocals()
+	instructions, positions := c.leaveScope()
+	for _, s := range freeSymbols {
+		c.loadCapture(s)
+	}
+	fn := &object.CompiledFunction{
+		Instructions: instructions,
+		NumLocals:    numLocals,
+		NumParams:    len(params),
+		ParamTypes:   object.ParamTypes(params),
+		ReturnT:      returnT,
+		Name:         name,
+		IsLambda:     lambda,
+		Generator:    generator,
+		Positions:    positions,
+	}
+	c.emit(OpClosure, c.addConstant(fn), len(freeSymbols))
+	return nil
+}
+
+// compileImport : run the modules code the first time it is imported, then
+// bind it as a Module or by the names imported.
+func (c *Compiler) compileImport(node *ast.ImportStatement) error {
+	if err := eval.CheckImport(c.runtime); err != nil {
+		return errors.New(err.Message)
+	}
+	path, err := eval.ResolveImport(c.runtime, node.Value, node.Pos().Filename())
+	if err != nil {
+		return err
+	}
+	idx, err := c.compileModule(node.Value, path)
+	if err != nil {
+		return err
+	}
+	c.emit(OpImport, idx)
+	c.emit(OpPop)
+
+	if node.Token != token.FROM {
+		name := node.Value
+		if node.Alias != nil {
+			name = node.Alias.Value
+		}
+		c.emit(OpModule, idx)
+		if err := c.defineAndStore(name, false); err != nil {
+			return err
+		}
+		c.emit(OpNone)
+		return nil
+	}
+	module := c.constants[idx].(*Module)
+	for _, n := range node.Names {
+		slot, ok := module.Slots[n.Value]
+		if !ok {
+			return fmt.Errorf("cannot import name '%s' from '%s'", n.Value, node.Value)
+		}
+		c.emit(OpGetGlobal, slot)
+		if err := c.defineAndStore(n.Value, false); err != nil {
+			return err
+		}
+	}
+	c.emit(OpNone)
+	return nil
+}
+
+// compileModule : compile the file at path into the Init function of a
+// Module with its own global symbol table. Each path is only compiled once,
+// the constant index of its Module is returned.
+func (c *Compiler) compileModule(name, path string) (int, error) {
+	if idx, ok := c.modules[path]; ok {
+		return idx, nil
+	}
+	for i, p := range c.importing {
+		if p == path {
+			chain := append(append([]string{}, c.importing[i:]...), path)
+			return 0, fmt.Errorf(eval.ImportCycleError, eval.FormatImportChain(chain))
+		}
+	}
+	program, err := eval.ParseModule(path)
+	if err != nil {
+		return 0, err
+	}
+	c.importing = append(c.importing, path)
+	defer func() { c.importing = c.importing[:len(c.importing)-1] }()
+
+	outer := c.symbolTable
+	c.symbolTable = NewModuleSymbolTable(c.globalTable())
+	c.scopes = append(c.scopes, compilationScope{instructions: Instructions{}})
+	c.scopeIndex++
+	c.declareGlobals(program.Statements)
+	c.declareCells(program)
+	if err := c.compileBlock(program.Statements); err != nil {
+		return 0, err
+	}
+	c.emit(OpReturnValue)
+	init := &object.CompiledFunction{
+		Instructions: c.currentInstructions(),
+		NumLocals:    c.symbolTable.NumLocals(),
+		Name:         "<module>",
+		Positions:    c.scopes[c.scopeIndex].positions,
+	}
+	module := &Module{
+		Name:  name,
+		Path:  path,
+		Slots: make(map[string]int),
+		Init:  init,
+	}
+	for name, sym := range c.symbolTable.store {
+		module.Slots[name] = sym.Index
+	}
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	c.symbolTable = outer
+
+	idx := c.addConstant(module)
+	c.modules[path] = idx
+	return idx, nil
+}
+
+func (c *Compiler) currentInstructions() Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emit : append an instruction to the current scope returning its position.
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	ins := Make(op, operands...)
+	pos := len(c.currentInstructions())
+	scope := &c.scopes[c.scopeIndex]
+	scope.instructions = append(scope.instructions, ins...)
+	if n := len(scope.positions); n == 0 || scope.positions[n-1].Pos != c.pos {
+		scope.positions = append(scope.positions, object.Position{Offset: pos, Pos: c.pos})
+	}
+	return pos
+}
+
+// at : attribute the instructions emitted to node, until the func returned
+// restores the node compiled before it.
+func (c *Compiler) at(node ast.Node) func() {
+	outer := c.pos
+	c.pos = node.Pos()
+	return func() { c.pos = outer }
+}
+
+// changeOperand : back patch the operand of a jump once its target is known.
+func (c *Compiler) changeOperand(pos int, operand int) {
+	op := Opcode(c.currentInstructions()[pos])
+	ins := Make(op, operand)
+	copy(c.scopes[c.scopeIndex].instructions[pos:], ins)
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, compilationScope{instructions: Instructions{}})
+	c.scopeIndex++
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+// enterBlock : give the variables defined by the statements compiled next a
+// scope of their own within the current one.
+func (c *Compiler) enterBlock() {
+	c.symbolTable = NewBlockSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveBlock() {
+	c.symbolTable = c.symbolTable.Outer
+}
+
+// compileScoped : compile the body of an if, for or try statement as a
+// block.
+func (c *Compiler) compileScoped(node ast.Node) error {
+	c.enterBlock()
+	defer c.leaveBlock()
+	return c.Compile(node)
+}
+
+func (c *Compiler) leaveScope() (Instructions, []object.Position) {
+	scope := c.scopes[c.scopeIndex]
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	c.symbolTable = c.symbolTable.Outer
+	return scope.instructions, scope.positions
+}
@@ -0,0 +1,179 @@
+package compiler
+
+import (
+	"dito/src/parser"
+	"dito/src/scanner"
+	"testing"
+)
+
+func TestInstructionsString(t *testing.T) {
+	ins := Instructions{}
+	for _, i := range [][]byte{
+		Make(OpConstant, 1),
+		Make(OpGetLocal, 255),
+		Make(OpClosure, 65535, 2),
+		Make(OpPop),
+	} {
+		ins = append(ins, i...)
+	}
+	expected := "0000 OpConstant 1\n0003 OpGetLocal 255\n0006 OpClosure 65535 2\n0011 OpPop\n"
+	if ins.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, ins.String())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []byte
+	}{
+		{"1 + 2", concat(
+			Make(OpConstant, 0),
+			Make(OpConstant, 1),
+			Make(OpBinary, 0),
+			Make(OpPop),
+		)},
+		{"let x = 1; x", concat(
+			Make(OpConstant, 0),
+			Make(OpSetGlobal, 0),
+			Make(OpNone),
+			Make(OpPop),
+			Make(OpGetGlobal, 0),
+			Make(OpPop),
+		)},
+		{"for true { 1 }", concat(
+			Make(OpNone),
+			Make(OpSetLocal, 0),
+			Make(OpTrue),
+			Make(OpJumpNotTrue, 17),
+			Make(OpConstant, 0),
+			Make(OpSetLocal, 0),
+			Make(OpJump, 4),
+			Make(OpGetLocal, 0),
+			Make(OpPop),
+		)},
+	}
+	for _, tt := range tests {
+		c := compileTestProgram(t, tt.input)
+		got := c.Bytecode().Instructions
+		if string(got) != string(tt.expected) {
+			t.Errorf("wrong instructions for %q.\nwant=\n%s\ngot=\n%s",
+				tt.input, Instructions(tt.expected), got)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1; x = 2", "identifier 'x' has a immutable value"},
+		{"y += 1", "identifier not found: 'y'"},
+		{"STDOUT = 1", "identifier 'STDOUT' has a immutable value"},
+		{"let mut n = 0; def f() { n = 1 }", "cannot assign to 'n' of an enclosing scope, declare it nonlocal"},
+		{"def f() { let mut n = 0; def g() { n += 1 } }", "cannot assign to 'n' of an enclosing scope, declare it nonlocal"},
+		{"def f() { nonlocal missing }", "no binding for nonlocal 'missing' found"},
+		{"let x = 1; let x = 2", "identifier 'x' is already declared in this scope"},
+		{"let mut x = 1; def x() { 1 }", "identifier 'x' is already declared in this scope"},
+		{"def f(a) { let a = 1 }", "identifier 'a' is already declared in this scope"},
+		{"for i in [1] { let i = 2 }", "identifier 'i' is already declared in this scope"},
+		{"struct P { x }; if true { let y = 1; struct y { z } }", "identifier 'y' is already declared in this scope"},
+		{"struct P { x, y, x }", "duplicate field 'x' in struct P"},
+		{"struct P { x; def x(self) { 1 } }", "duplicate name 'x' for a method of struct P"},
+		{"struct P { x; def m(self) { 1 }; def m(self) { 2 } }", "duplicate name 'm' for a method of struct P"},
+	}
+	for _, tt := range tests {
+		p := parser.New(scanner.Init(tt.input))
+		program := p.ParseProgram()
+		err := New().Compile(program)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error for %q. want=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestResolveFree(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a", false)
+	outer := NewEnclosedSymbolTable(global)
+	outer.Define("b", true)
+	inner := NewEnclosedSymbolTable(outer)
+	inner.Define("c", false)
+
+	expected := map[string]Symbol{
+		"a": {Name: "a", Scope: GlobalScope, Index: 0},
+		"b": {Name: "b", Scope: FreeScope, Index: 0, Mutable: true},
+		"c": {Name: "c", Scope: LocalScope, Index: 0},
+	}
+	for name, want := range expected {
+		got, ok := inner.Resolve(name)
+		if !ok {
+			t.Fatalf("name %s not resolvable", name)
+		}
+		if got != want {
+			t.Errorf("wrong symbol for %s. want=%+v, got=%+v", name, want, got)
+		}
+	}
+	if len(inner.FreeSymbols) != 1 || inner.FreeSymbols[0].Scope != LocalScope {
+		t.Errorf("free symbols not recorded. got=%+v", inner.FreeSymbols)
+	}
+}
+
+func TestBlockSymbols(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a", false)
+	fn := NewEnclosedSymbolTable(global)
+	fn.Define("b", false)
+	block := NewBlockSymbolTable(fn)
+	block.Define("c", false)
+	inner := NewBlockSymbolTable(block)
+	inner.Define("d", false)
+
+	expected := map[string]Symbol{
+		"a": {Name: "a", Scope: GlobalScope, Index: 0},
+		"b": {Name: "b", Scope: LocalScope, Index: 0},
+		"c": {Name: "c", Scope: LocalScope, Index: 1},
+		"d": {Name: "d", Scope: LocalScope, Index: 2},
+	}
+	for name, want := range expected {
+		got, ok := inner.Resolve(name)
+		if !ok {
+			t.Fatalf("name %s not resolvable", name)
+		}
+		if got != want {
+			t.Errorf("wrong symbol for %s. want=%+v, got=%+v", name, want, got)
+		}
+	}
+	if len(fn.FreeSymbols) != 0 {
+		t.Errorf("block variables captured as free. got=%+v", fn.FreeSymbols)
+	}
+	// slots of a finished block are reused by the next.
+	if sym := NewBlockSymbolTable(fn).Define("e", false); sym.Index != 1 {
+		t.Errorf("block slot not reused. want=1, got=%d", sym.Index)
+	}
+	if fn.NumLocals() != 3 {
+		t.Errorf("wrong number of locals. want=3, got=%d", fn.NumLocals())
+	}
+}
+
+func compileTestProgram(t *testing.T, input string) *Compiler {
+	p := parser.New(scanner.Init(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors", len(p.Errors()))
+	}
+	c := New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	return c
+}
+
+func concat(ins ...[]byte) []byte {
+	out := []byte{}
+	for _, i := range ins {
+		out = append(out, i...)
+	}
+	return out
+}
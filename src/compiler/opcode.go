@@ -0,0 +1,237 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions : a flat stream of opcodes each followed by their operands.
+// Operands are stored big endian with widths given by the opcodes Definition.
+type Instructions []byte
+
+// Opcode : a single bytecode instruction understood by the vm.
+type Opcode byte
+
+// Opcodes emitted by the compiler. Comments describe the operands followed
+// by the effect on the stack.
+const (
+	OpConstant Opcode = iota // idx: push constants[idx]
+	OpPop                    // pop the top of the stack
+	OpNone                   // push None
+	OpTrue                   // push true
+	OpFalse                  // push false
+
+	OpBinary // op: pop b, a; push BinaryOperators[op](a, b)
+	OpMinus  // pop a; push -a
+	OpNot    // pop a; push not a
+
+	OpJump             // addr: jump to addr
+	OpJumpNotTrue      // addr: pop a; jump to addr if a is not true
+	OpJumpNotTrueOrPop // addr: jump to addr if a on top is not true, else pop a
+	OpJumpTrueOrPop    // addr: jump to addr if a on top is true, else pop a
+
+	OpGetGlobal // idx: push globals[idx]
+	OpSetGlobal // idx: pop a; globals[idx] = a
+	OpGetLocal  // idx: push locals[idx]
+	OpSetLocal  // idx: pop a; locals[idx] = a
+	OpGetFree   // idx: push the current closures free[idx]
+
+	OpNewCell     // idx: pop a; locals[idx] = a new cell holding a
+	OpGetCell     // idx: push the value of the cell in locals[idx]
+	OpSetCell     // idx: pop a; set the value of the cell in locals[idx] to a
+	OpGetFreeCell // idx: push the value of the cell in free[idx]
+	OpSetFreeCell // idx: pop a; set the value of the cell in free[idx] to a
+	OpCheckType   // idx, name: error unless a on top has type constants[idx], to assign to constants[name]
+
+	OpArray    // n: pop n elements; push Array
+	OpDict     // n: pop n key value pairs; push Dict
+	OpIndex    // pop key, a; push a[key]
+	OpSlice    // pop end, start, a; push a[start:end]
+	OpSetIndex // op: pop val, key, a; a[key] = val or a[key] op= val if op > 0
+	OpGetAttr  // idx: pop a; push a.name where name is constants[idx]
+	OpSetAttr  // idx, op: pop val, a; a.name = val or a.name op= val if op > 0
+	OpStruct   // idx, n: pop n name method pairs; push a copy of constants[idx]
+
+	OpCall           // argc: call the function below the args
+	OpTailCall       // argc: call the function below the args in place of the current frame
+	OpReturnValue    // pop a; return a from the current frame
+	OpClosure        // idx, n: pop n free variables; push Closure of constants[idx]
+	OpCurrentClosure // push the closure of the current frame
+	OpYield          // pop a; suspend the generator of the current frame, which yields a
+
+	OpGetIter  // pop a; push an iterator over a
+	OpIterNext // addr: push the iterators next item or pop it and jump to addr
+
+	OpTry    // addr: on error unwind to here, push the Exception and jump to addr
+	OpEndTry // remove the handler set by the last OpTry
+	OpRaise  // pop a; raise a as an error
+
+	OpImport // idx: call the Init of module constants[idx] the first time, else push None
+	OpModule // idx: push the module described by constants[idx]
+)
+
+// Definition : a human readable name and the byte width of each operand.
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+	OpNone:     {"OpNone", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+
+	OpBinary: {"OpBinary", []int{1}},
+	OpMinus:  {"OpMinus", []int{}},
+	OpNot:    {"OpNot", []int{}},
+
+	OpJump:             {"OpJump", []int{2}},
+	OpJumpNotTrue:      {"OpJumpNotTrue", []int{2}},
+	OpJumpNotTrueOrPop: {"OpJumpNotTrueOrPop", []int{2}},
+	OpJumpTrueOrPop:    {"OpJumpTrueOrPop", []int{2}},
+
+	OpGetGlobal: {"OpGetGlobal", []int{2}},
+	OpSetGlobal: {"OpSetGlobal", []int{2}},
+	OpGetLocal:  {"OpGetLocal", []int{2}},
+	OpSetLocal:  {"OpSetLocal", []int{2}},
+	OpGetFree:   {"OpGetFree", []int{2}},
+
+	OpNewCell:     {"OpNewCell", []int{2}},
+	OpGetCell:     {"OpGetCell", []int{2}},
+	OpSetCell:     {"OpSetCell", []int{2}},
+	OpGetFreeCell: {"OpGetFreeCell", []int{2}},
+	OpSetFreeCell: {"OpSetFreeCell", []int{2}},
+	OpCheckType:   {"OpCheckType", []int{2, 2}},
+
+	OpArray:    {"OpArray", []int{2}},
+	OpDict:     {"OpDict", []int{2}},
+	OpIndex:    {"OpIndex", []int{}},
+	OpSlice:    {"OpSlice", []int{}},
+	OpSetIndex: {"OpSetIndex", []int{1}},
+	OpGetAttr:  {"OpGetAttr", []int{2}},
+	OpSetAttr:  {"OpSetAttr", []int{2, 1}},
+	OpStruct:   {"OpStruct", []int{2, 1}},
+
+	OpCall:           {"OpCall", []int{1}},
+	OpTailCall:       {"OpTailCall", []int{1}},
+	OpReturnValue:    {"OpReturnValue", []int{}},
+	OpClosure:        {"OpClosure", []int{2, 2}},
+	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+	OpYield:          {"OpYield", []int{}},
+
+	OpGetIter:  {"OpGetIter", []int{}},
+	OpIterNext: {"OpIterNext", []int{2}},
+
+	OpTry:    {"OpTry", []int{2}},
+	OpEndTry: {"OpEndTry", []int{}},
+	OpRaise:  {"OpRaise", []int{}},
+
+	OpImport: {"OpImport", []int{2}},
+	OpModule: {"OpModule", []int{2}},
+}
+
+// BinaryOperators : operators addressed by the operand of OpBinary and
+// OpSetIndex. Each name is a key of object.BinaryOps.
+var BinaryOperators = []string{
+	"+", "-", "*", "/", "//", "%", "**", "++",
+	"==", "!=", "<", ">", "<=", ">=",
+	"<<", ">>", "&", "|", "^", "in",
+}
+
+func binaryOperator(name string) (int, bool) {
+	for i, op := range BinaryOperators {
+		if op == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Lookup : return the definition of an opcode.
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make : encode an opcode and its operands into an instruction.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+	offset := 1
+	for i, o := range operands {
+		switch def.OperandWidths[i] {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += def.OperandWidths[i]
+	}
+	return instruction
+}
+
+// ReadOperands : decode the operands of an instruction returning them with
+// the number of bytes read.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+// ReadUint16 : decode a two byte operand.
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// String : disassemble the instructions one per line.
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+		i += 1 + read
+	}
+	return out.String()
+}
+
+func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+	return fmt.Sprintf("ERROR: unhandled operand count for %s", def.Name)
+}
@@ -0,0 +1,193 @@
+package compiler
+
+// SymbolScope : where the value of a resolved identifier lives at runtime.
+type SymbolScope string
+
+// Symbol scopes.
+const (
+	GlobalScope   SymbolScope = "GLOBAL"
+	LocalScope    SymbolScope = "LOCAL"
+	FreeScope     SymbolScope = "FREE"
+	FunctionScope SymbolScope = "FUNCTION"
+)
+
+// Symbol : a resolved identifier and the slot its value is stored in.
+type Symbol struct {
+	Name    string
+	Scope   SymbolScope
+	Index   int
+	Mutable bool
+	Cell    bool   // the slot holds a cell shared with the closures capturing it.
+	Type    string // the annotated type of its values, empty for any.
+}
+
+// SymbolTable : the compile time counterpart of object.Environment. There is
+// one table per function body, globals live in the outermost table. Blocks
+// have tables of their own whose locals are stored in the frame of the
+// function, or the main frame at the top level.
+type SymbolTable struct {
+	Outer       *SymbolTable
+	FreeSymbols []Symbol
+
+	store          map[string]Symbol
+	numDefinitions int
+	maxDefinitions int             // the number of local slots the frame needs.
+	globals        *[]string       // names of the global slots shared by all modules.
+	block          bool            // the table of a block within a function.
+	cells          map[string]bool // names captured by closures within the function.
+	nonlocals      map[string]bool // names declared nonlocal in the function.
+	declared       map[string]bool // names declared by the statements of the scope.
+}
+
+// NewSymbolTable : return a new global symbol table.
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol), globals: &[]string{}}
+}
+
+// NewModuleSymbolTable : return the global symbol table of an imported
+// module. Its globals are given slots after those of the program.
+func NewModuleSymbolTable(program *SymbolTable) *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol), globals: program.globals}
+}
+
+// NewEnclosedSymbolTable : return a symbol table for a function body.
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol), Outer: outer}
+}
+
+// NewBlockSymbolTable : return a symbol table for a block. Its slots follow
+// those in use by the tables around it, and are free for reuse once the
+// block has been compiled.
+func NewBlockSymbolTable(outer *SymbolTable) *SymbolTable {
+	return &SymbolTable{
+		store:          make(map[string]Symbol),
+		Outer:          outer,
+		block:          true,
+		numDefinitions: outer.numDefinitions,
+	}
+}
+
+// frame : the table of the function, or module, the table is in.
+func (s *SymbolTable) frame() *SymbolTable {
+	for s.block {
+		s = s.Outer
+	}
+	return s
+}
+
+// Define : give a name a slot in the current scope. Redefining a name reuses
+// its slot so that values captured earlier stay addressable.
+func (s *SymbolTable) Define(name string, mutable bool) Symbol {
+	if sym, ok := s.store[name]; ok && sym.Scope != FreeScope && sym.Scope != FunctionScope {
+		sym.Mutable = mutable
+		s.store[name] = sym
+		return sym
+	}
+	sym := Symbol{Name: name, Mutable: mutable}
+	if s.Outer == nil {
+		sym.Scope = GlobalScope
+		sym.Index = len(*s.globals)
+		*s.globals = append(*s.globals, name)
+	} else {
+		frame := s.frame()
+		sym.Scope = LocalScope
+		sym.Index = s.numDefinitions
+		sym.Cell = frame.cells[name]
+		s.numDefinitions++
+		frame.maxDefinitions = max(frame.maxDefinitions, s.numDefinitions)
+	}
+	s.store[name] = sym
+	return sym
+}
+
+// NumLocals : the number of local slots needed by the frame of the table.
+func (s *SymbolTable) NumLocals() int {
+	return s.frame().maxDefinitions
+}
+
+// Annotate : give the values of name in the current scope a type, checked
+// whenever the name is assigned to.
+func (s *SymbolTable) Annotate(name, typ string) Symbol {
+	sym := s.store[name]
+	sym.Type = typ
+	s.store[name] = sym
+	return sym
+}
+
+// Declare : record that name is declared in the scope of the table. Returns
+// false when it already is, as a name can only be declared once per scope.
+func (s *SymbolTable) Declare(name string) bool {
+	if s.declared[name] {
+		return false
+	}
+	if s.declared == nil {
+		s.declared = make(map[string]bool)
+	}
+	s.declared[name] = true
+	return true
+}
+
+// DefineFunctionName : let a function refer to itself by name.
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	sym := Symbol{Name: name, Scope: FunctionScope}
+	s.store[name] = sym
+	return sym
+}
+
+// Resolve : find the slot of a name looking outwards through the enclosing
+// scopes. Locals of enclosing functions are captured as free variables.
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	sym, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return sym, ok
+	}
+	sym, ok = s.Outer.Resolve(name)
+	if !ok || sym.Scope == GlobalScope || s.block {
+		return sym, ok
+	}
+	return s.defineFree(sym), true
+}
+
+// Nonlocal : declare name nonlocal in the function of the table, letting it
+// assign to the variable of an enclosing scope. Returns false when there is
+// no such variable.
+func (s *SymbolTable) Nonlocal(name string) bool {
+	frame := s.frame()
+	if frame.Outer == nil {
+		return false
+	}
+	if _, ok := frame.Outer.Resolve(name); !ok {
+		return false
+	}
+	if frame.nonlocals == nil {
+		frame.nonlocals = make(map[string]bool)
+	}
+	frame.nonlocals[name] = true
+	return true
+}
+
+// IsNonlocal : report whether name was declared nonlocal in the function of
+// the table.
+func (s *SymbolTable) IsNonlocal(name string) bool {
+	return s.frame().nonlocals[name]
+}
+
+// InFunction : report whether the table is within a function, rather than
+// at the top level of the program or a module.
+func (s *SymbolTable) InFunction() bool {
+	return s.frame().Outer != nil
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+	sym := Symbol{
+		Name:    original.Name,
+		Scope:   FreeScope,
+		Index:   len(s.FreeSymbols) - 1,
+		Mutable: original.Mutable,
+		Cell:    original.Cell,
+		Type:    original.Type,
+	}
+	s.store[original.Name] = sym
+	return sym
+}
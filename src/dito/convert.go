@@ -0,0 +1,178 @@
+package dito
+
+import (
+	"dito/src/object"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Char : a dito Char in Go. A rune is an int32 so converts to an Int, a Char
+// converts to a Char.
+type Char rune
+
+// Decimal : a dito Decimal in Go, its exact digits such as "1.50". It is kept
+// apart from string so a Decimal and a String do not become the same key.
+type Decimal string
+
+// ToObject : convert a Go value to a dito object. Supported are nil, bools,
+// integers including *big.Int, floats, *big.Rat, strings, Chars, Decimals, slices,
+// arrays and maps with convertible keys and values, and values which already
+// are an object.Object.
+func ToObject(value interface{}) (object.Object, error) {
+	switch v := value.(type) {
+	case nil:
+		return object.NONE, nil
+	case object.Object:
+		return v, nil
+	case bool:
+		return object.NewBool(v), nil
+	case int:
+		return object.NewInt(v), nil
+	case Char:
+		return object.NewChar(rune(v)), nil
+	case Decimal:
+		if d, ok := object.ParseDecimal(string(v)); ok {
+			return d, nil
+		}
+		return nil, fmt.Errorf("cannot convert %q to a Decimal", string(v))
+	case float64:
+		return object.NewFloat(v), nil
+	case *big.Int:
+		return object.NewBigInt(new(big.Int).Set(v)), nil
+	case *big.Rat:
+		return object.NewRational(new(big.Rat).Set(v)), nil
+	case string:
+		return object.NewString(v), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return object.NewInt(int(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.NewBigInt(new(big.Int).SetUint64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return object.NewFloat(rv.Float()), nil
+	case reflect.Bool:
+		return object.NewBool(rv.Bool()), nil
+	case reflect.String:
+		return object.NewString(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]object.Object, rv.Len())
+		for i := range elements {
+			el, err := ToObject(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = el
+		}
+		return object.NewArray(elements, -1), nil
+	case reflect.Map:
+		dict := object.NewDict()
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := ToObject(iter.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+			val, err := ToObject(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			if res := dict.SetItem(key, val); res.Type() == object.ErrorType {
+				return nil, fmt.Errorf("%s", res.(*object.Error).Message)
+			}
+		}
+		return dict, nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return object.NONE, nil
+		}
+		return ToObject(rv.Elem().Interface())
+	}
+	return nil, fmt.Errorf("cannot convert %T to a dito value", value)
+}
+
+// FromObject : convert a dito object to a Go value. None is nil, Int is int,
+// or *big.Int when too large for an int, Float is float64, Rational is
+// *big.Rat, Decimal is Decimal, Bool is bool, Char is
+// Char, String is string, Array is []interface{}
+// and Dict is map[string]interface{} when all its keys are strings, else
+// map[interface{}]interface{}. Struct instances are a map[string]interface{}
+// of their fields. Other objects are returned unconverted, as is an Array,
+// Dict or Instance within itself so that a value holding itself converts.
+func FromObject(obj object.Object) interface{} {
+	return fromObject(obj, map[object.Object]bool{})
+}
+
+// fromObject : FromObject within the containers seen.
+func fromObject(obj object.Object, seen map[object.Object]bool) interface{} {
+	switch obj.(type) {
+	case *object.Array, *object.Dict, *object.Instance:
+		if seen[obj] {
+			return obj
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+	}
+	switch v := obj.(type) {
+	case nil, *object.None:
+		return nil
+	case *object.Bool:
+		return v.Value
+	case *object.Int:
+		if v.IsBig() {
+			return new(big.Int).Set(v.Big)
+		}
+		return v.Value
+	case *object.Float:
+		return v.Value
+	case *object.Rational:
+		return new(big.Rat).Set(v.Value)
+	case *object.Decimal:
+		return Decimal(v.Inspect())
+	case *object.Char:
+		return Char(v.Value)
+	case *object.String:
+		return v.Value
+	case *object.Array:
+		values := make([]interface{}, len(v.Elements))
+		for i, el := range v.Elements {
+			values[i] = fromObject(el, seen)
+		}
+		return values
+	case *object.Dict:
+		return fromDict(v, seen)
+	case *object.Instance:
+		fields := make(map[string]interface{}, len(v.Fields))
+		for i, name := range v.Struct.Fields {
+			fields[name] = fromObject(v.Fields[i], seen)
+		}
+		return fields
+	default:
+		return obj
+	}
+}
+
+func fromDict(dict *object.Dict, seen map[object.Object]bool) interface{} {
+	strs := make(map[string]interface{}, dict.Len)
+	for _, item := range dict.Items {
+		key, ok := item.Key.(*object.String)
+		if !ok {
+			break
+		}
+		strs[key.Value] = fromObject(item.Value, seen)
+	}
+	if len(strs) == len(dict.Items) {
+		return strs
+	}
+	values := make(map[interface{}]interface{}, dict.Len)
+	for _, item := range dict.Items {
+		key := fromObject(item.Key, seen)
+		if !reflect.TypeOf(key).Comparable() {
+			key = item.Key
+		}
+		values[key] = fromObject(item.Value, seen)
+	}
+	return values
+}
@@ -0,0 +1,235 @@
+/*Package dito embeds the dito interpreter in Go programs. An Interpreter
+evaluates source code in its own global environment, writes to its own
+standard streams and can call functions provided by the host. Values pass
+between Go and dito by the conversions of ToObject and FromObject.
+*/
+package dito
+
+import (
+	"context"
+	"dito/src/eval"
+	"dito/src/object"
+	"dito/src/parser"
+	"dito/src/scanner"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Options : configure a new Interpreter. Nil streams are replaced by ones
+// which read nothing and discard what is written. Limits apply to each call
+// of Eval or Call separately. Code can only use the builtins of the groups
+// in Capabilities, using any other raises a PermissionError, so by default
+// it cannot print, touch the file system, read the clock or make random
+// numbers. Decimal sets the places and rounding of Decimal arithmetic, nil
+// uses object.DefaultDecimalContext. ImportPath lists the directories searched
+// for modules not next to the importing file, nil uses
+// object.DefaultImportPath.
+type Options struct {
+	Stdin        io.Reader
+	Stdout       io.Writer
+	Stderr       io.Writer
+	Limits       object.Limits
+	Capabilities object.Capability
+	Decimal      *object.DecimalContext
+	ImportPath   []string
+}
+
+// Interpreter : evaluates dito code. Globals defined by one call to Eval are
+// visible to later ones, though a generator is stopped once the call which
+// made it returns. An Interpreter must not be used by more than one goroutine
+// at a time.
+type Interpreter struct {
+	runtime *object.Runtime
+	env     *object.Environment
+}
+
+// Func : a Go function callable from dito. The arguments are converted with
+// FromObject and the result with ToObject, a non nil error is raised as a
+// dito Error.
+type Func func(args ...interface{}) (interface{}, error)
+
+// Error : an error raised by dito code and not caught, or the errors found
+// parsing it. Kind is set for errors raised by the interpreter itself.
+type Error struct {
+	Message   string
+	Kind      string
+	Traceback string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Is : errors.Is(err, ErrLimit) reports whether err is a LimitError and
+// errors.Is(err, ErrPermission) whether it is a PermissionError.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrLimit:
+		return e.Kind == object.LimitError
+	case ErrPermission:
+		return e.Kind == object.PermissionError
+	}
+	return false
+}
+
+// ErrLimit : matches the errors returned when evaluation exceeds one of the
+// Limits of the interpreter or its context is cancelled. Their type is
+// LimitError and dito code cannot catch them with try.
+var ErrLimit = errors.New("dito: limit exceeded")
+
+// ErrPermission : matches the errors returned when code uses a builtin or
+// variable outside of the Capabilities of the interpreter.
+var ErrPermission = errors.New("dito: permission denied")
+
+// New : return an interpreter using the streams of opts.
+func New(opts Options) *Interpreter {
+	if opts.Stdin == nil {
+		opts.Stdin = strings.NewReader("")
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = ioutil.Discard
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = ioutil.Discard
+	}
+	rt := object.NewRuntime(opts.Stdin, opts.Stdout, opts.Stderr)
+	rt.Limits = opts.Limits
+	rt.Capabilities = opts.Capabilities
+	if opts.Decimal != nil {
+		rt.Decimal = *opts.Decimal
+	}
+	if opts.ImportPath != nil {
+		rt.ImportPath = append([]string{}, opts.ImportPath...)
+	}
+	rt.Builtins = make(map[string]*object.Builtin, len(eval.Builtins))
+	for name, builtin := range eval.Builtins {
+		rt.Builtins[name] = builtin
+	}
+	return &Interpreter{runtime: rt, env: object.RuntimeEnvironment(rt)}
+}
+
+// Eval : run src returning the value of its last statement. Imports are
+// searched for relative to the working directory.
+func (i *Interpreter) Eval(src string) (interface{}, error) {
+	return i.EvalFile(context.Background(), "<eval>", src)
+}
+
+// EvalContext : run src like Eval, stopping with a LimitError if ctx is
+// cancelled first.
+func (i *Interpreter) EvalContext(ctx context.Context, src string) (interface{}, error) {
+	return i.EvalFile(ctx, "<eval>", src)
+}
+
+// EvalFile : run src as the contents of filename, which is used in
+// tracebacks and to find the modules it imports.
+func (i *Interpreter) EvalFile(ctx context.Context, filename, src string) (interface{}, error) {
+	p := parser.New(scanner.InitFile(filename, src+"\n"))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		var trace strings.Builder
+		p.PrintParseErrors(&trace, errs)
+		return nil, &Error{
+			Message:   fmt.Sprintf("%d parse errors in %s", len(errs), filename),
+			Traceback: trace.String(),
+		}
+	}
+	defer i.runtime.Begin(ctx)()
+	return i.result(eval.Eval(program, i.env))
+}
+
+// Call : call the function or builtin name with args converted by ToObject.
+func (i *Interpreter) Call(name string, args ...interface{}) (interface{}, error) {
+	return i.CallContext(context.Background(), name, args...)
+}
+
+// CallContext : call name like Call, stopping with a LimitError if ctx is
+// cancelled first.
+func (i *Interpreter) CallContext(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	fn, ok := i.env.Get(name)
+	if !ok {
+		if fn, ok = i.runtime.Builtins[name]; !ok {
+			return nil, fmt.Errorf("no function named '%s'", name)
+		}
+	}
+	objs := make([]object.Object, len(args))
+	for idx, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, err
+		}
+		objs[idx] = obj
+	}
+	defer i.runtime.Begin(ctx)()
+	return i.result(eval.ApplyFunction(fn, objs, i.env))
+}
+
+// Get : return the value of the global variable name.
+func (i *Interpreter) Get(name string) (interface{}, bool) {
+	val, ok := i.env.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return FromObject(val), true
+}
+
+// Set : define the immutable global variable name as value converted by
+// ToObject.
+func (i *Interpreter) Set(name string, value interface{}) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+	i.env.Set(name, obj, false)
+	return nil
+}
+
+// Register : make the builtin callable from dito by its Name, replacing any
+// builtin with the same name. Calls with arguments which do not match its
+// ArgC and ArgT raise an Error without calling its Fn.
+func (i *Interpreter) Register(builtin *object.Builtin) error {
+	if err := builtin.Validate(); err != nil {
+		return err
+	}
+	i.runtime.Builtins[builtin.Name] = builtin
+	return nil
+}
+
+// RegisterFunc : make fn callable from dito as the builtin name with any
+// number of arguments of any type, replacing any builtin with the same name.
+func (i *Interpreter) RegisterFunc(name string, fn Func) error {
+	return i.Register(&object.Builtin{
+		Name:    name,
+		Fn:      hostFunction(name, fn),
+		Info:    "Function provided by the host program",
+		ArgC:    -1,
+		ArgT:    []string{"Any..."},
+		ReturnT: "Any",
+	})
+}
+
+func hostFunction(name string, fn Func) object.BuiltinFunction {
+	return func(env *object.Environment, args ...object.Object) object.Object {
+		values := make([]interface{}, len(args))
+		for idx, arg := range args {
+			values[idx] = FromObject(arg)
+		}
+		result, err := fn(values...)
+		if err != nil {
+			return object.NewError("%s: %s", name, err)
+		}
+		obj, err := ToObject(result)
+		if err != nil {
+			return object.NewError("%s: %s", name, err)
+		}
+		return obj
+	}
+}
+
+// result : convert the result of evaluation for the host.
+func (i *Interpreter) result(obj object.Object) (interface{}, error) {
+	if err, ok := obj.(*object.Error); ok {
+		return nil, &Error{Message: err.Message, Kind: err.Kind, Traceback: err.Traceback()}
+	}
+	return FromObject(obj), nil
+}
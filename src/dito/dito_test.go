@@ -0,0 +1,564 @@
+package dito
+
+import (
+	"bytes"
+	"context"
+	"dito/src/object"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEval(t *testing.T) {
+	interp := New(Options{})
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 + 2", 3},
+		{"let x = 2.5", nil},
+		{"x * 2", 5.0},
+		{`"a" ++ "b"`, "ab"},
+		{`[1, "a", true]`, []interface{}{1, "a", true}},
+		{`{"a": [1]}`, map[string]interface{}{"a": []interface{}{1}}},
+		{"{1: 2}", map[interface{}]interface{}{1: 2}},
+		{"struct P { x, y }; P(1, 2)", map[string]interface{}{"x": 1, "y": 2}},
+	}
+	for _, tt := range tests {
+		got, err := interp.Eval(tt.input)
+		if err != nil {
+			t.Fatalf("eval of %q failed: %s", tt.input, err)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("eval of %q. want=%#v, got=%#v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	interp := New(Options{})
+	_, err := interp.Eval("def f() { 1 + \"a\" }\nf()")
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error. got=%T (%v)", err, err)
+	}
+	if e.Message != "mis matched types: Int, String" {
+		t.Errorf("wrong message. got=%q", e.Message)
+	}
+	if !strings.Contains(e.Traceback, `File "<eval>", line 1, in f`) {
+		t.Errorf("traceback missing frame. got=\n%s", e.Traceback)
+	}
+	if _, err := interp.Eval("let = 1"); err == nil || !strings.Contains(err.(*Error).Traceback, "PARSE ERROR") {
+		t.Errorf("expected parse error. got=%v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	interp := New(Options{})
+	if _, err := interp.Eval("def sum(xs, d) { let mut s = d[\"start\"]; for x in xs { s += x }; s }"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := interp.Call("sum", []int{1, 2, 3}, map[string]int{"start": 10})
+	if err != nil || got != 16 {
+		t.Errorf("wrong result of sum. got=%v, %v", got, err)
+	}
+	if got, err := interp.Call("len", "abc"); err != nil || got != 3 {
+		t.Errorf("wrong result of builtin len. got=%v, %v", got, err)
+	}
+	if _, err := interp.Call("missing"); err == nil {
+		t.Errorf("expected error calling a missing function")
+	}
+	if _, err := interp.Call("sum", make(chan int)); err == nil {
+		t.Errorf("expected error converting a channel")
+	}
+}
+
+func TestStreams(t *testing.T) {
+	var out1, out2, errOut bytes.Buffer
+	first := New(Options{Stdout: &out1, Stderr: &errOut, Capabilities: object.CapStdio})
+	second := New(Options{Stdout: &out2, Stdin: strings.NewReader("input"), Capabilities: object.CapStdio})
+	if _, err := first.Eval(`print("one", 1); write(STDERR, "oops")`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Eval(`print("two"); write(STDOUT, read(STDIN))`); err != nil {
+		t.Fatal(err)
+	}
+	if out1.String() != "one 1\n" || errOut.String() != "oops" {
+		t.Errorf("wrong output of first. got=%q, %q", out1.String(), errOut.String())
+	}
+	if out2.String() != "two\ninput" {
+		t.Errorf("wrong output of second. got=%q", out2.String())
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interp := New(Options{})
+	interp.RegisterFunc("join", func(args ...interface{}) (interface{}, error) {
+		parts := []string{}
+		for _, arg := range args[0].([]interface{}) {
+			parts = append(parts, arg.(string))
+		}
+		return strings.Join(parts, args[1].(string)), nil
+	})
+	interp.RegisterFunc("fail", func(args ...interface{}) (interface{}, error) {
+		return nil, errors.New("host failure")
+	})
+	if got, err := interp.Eval(`join(["a", "b"], "-")`); err != nil || got != "a-b" {
+		t.Errorf("wrong result of join. got=%v, %v", got, err)
+	}
+	if got, err := interp.Eval("try { fail() } catch e { string(e) }"); err != nil || got != "fail: host failure" {
+		t.Errorf("host error not raised. got=%v, %v", got, err)
+	}
+	if _, err := New(Options{}).Eval("join([], \"\")"); err == nil {
+		t.Errorf("host function leaked into another interpreter")
+	}
+}
+
+func TestDecimalContext(t *testing.T) {
+	interp := New(Options{Decimal: &object.DecimalContext{Places: 2, Rounding: object.RoundDown}})
+	if got, err := interp.Eval("string(decimal(2) / 3)"); err != nil || got != "0.66" {
+		t.Errorf("wrong Decimal context. got=%v, %v", got, err)
+	}
+	if got, err := New(Options{}).Eval("string(decimal(2) / 3)"); err != nil || got != "0.6666666666666666666666666667" {
+		t.Errorf("wrong default Decimal context. got=%v, %v", got, err)
+	}
+	got, err := interp.Eval("rational(1, 3) * 2")
+	if r, ok := got.(*big.Rat); err != nil || !ok || r.RatString() != "2/3" {
+		t.Errorf("Rational not converted to *big.Rat. got=%v, %v", got, err)
+	}
+	if got, err := interp.Eval(`[decimal("1.50"), decimal(2) / 3]`); err != nil || fmt.Sprint(got) != "[1.50 0.66]" {
+		t.Errorf("Decimal not converted to its digits. got=%#v, %v", got, err)
+	}
+	got, err = interp.Eval(`{decimal("1.5"): 1, "1.5": 2}`)
+	if keys, ok := got.(map[interface{}]interface{}); err != nil || !ok || keys[Decimal("1.5")] != 1 || keys["1.5"] != 2 {
+		t.Errorf("Decimal key converted like a String. got=%#v, %v", got, err)
+	}
+	if obj, err := ToObject(Decimal("1.50")); err != nil || obj.Type() != object.DecimalType || obj.Inspect() != "1.50" {
+		t.Errorf("Decimal not converted to Decimal. got=%v, %v", obj, err)
+	}
+	if _, err := ToObject(Decimal("1.5.0")); err == nil {
+		t.Errorf("expected error converting an invalid Decimal")
+	}
+	if obj, err := ToObject(big.NewRat(3, 4)); err != nil || obj.Inspect() != "3/4" {
+		t.Errorf("*big.Rat not converted to Rational. got=%v, %v", obj, err)
+	}
+}
+
+func TestGeneratorsStopped(t *testing.T) {
+	interp := New(Options{})
+	if _, err := interp.Eval("def nat() { let mut i = 0; for true { yield i; i += 1 } }"); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let mut s = 0; for x in nat() { if x > 2 { break }; s += x }; s", 3},
+		{"def first() { for x in nat() { return x } }; first()", 0},
+		{"let it = nat(); for x in it { if x > 0 { break } }; [next(it), next(it)]", []interface{}{2, 3}},
+		{"let kept = nat(); next(kept)", 0},
+	}
+	for _, tt := range tests {
+		if got, err := interp.Eval(tt.input); err != nil || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("eval of %q. want=%v, got=%v, %v", tt.input, tt.expected, got, err)
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("generators left suspended. goroutines before=%d, after=%d", before, after)
+	}
+	if _, err := interp.Eval("next(kept)"); err == nil || err.Error() != "next called on an exhausted Iterator" {
+		t.Errorf("generator outlived the evaluation which made it. got=%v", err)
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for dir, value := range map[string]string{first: "1", second: "2"} {
+		if err := os.WriteFile(filepath.Join(dir, "config.dito"), []byte("let value = "+value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for dir, expected := range map[string]int{first: 1, second: 2} {
+		interp := New(Options{Capabilities: object.CapFilesystem, ImportPath: []string{dir}})
+		if got, err := interp.Eval("import config; config.value"); err != nil || got != expected {
+			t.Errorf("wrong module imported from %s. want=%d, got=%v, %v", dir, expected, got, err)
+		}
+	}
+	if _, err := New(Options{Capabilities: object.CapFilesystem}).Eval("import config"); err == nil {
+		t.Errorf("import path of another interpreter searched")
+	}
+}
+
+func TestConvertCycle(t *testing.T) {
+	got, err := New(Options{}).Eval("let a = [0, 1]; a[0] = a; a")
+	values, ok := got.([]interface{})
+	if err != nil || !ok || len(values) != 2 || values[1] != 1 {
+		t.Fatalf("wrong conversion of a cyclic Array. got=%#v, %v", got, err)
+	}
+	if inner, ok := values[0].(*object.Array); !ok || inner.Inspect() != "[..., 1]" {
+		t.Errorf("Array within itself not left unconverted. got=%#v", values[0])
+	}
+}
+
+func TestConvertChar(t *testing.T) {
+	if got, err := New(Options{}).Eval(`"héllo"[1]`); err != nil || got != Char('é') {
+		t.Errorf("Char not converted to Char. got=%#v, %v", got, err)
+	}
+	obj, err := ToObject([]Char{'é', '!'})
+	if err != nil || obj.Inspect() != "['é', '!']" {
+		t.Errorf("Chars not converted to Chars. got=%v, %v", obj, err)
+	}
+	obj, err = ToObject([]interface{}{int32(5), 'é'})
+	if err != nil || obj.Inspect() != "[5, 233]" {
+		t.Errorf("int32 not converted to Int. got=%v, %v", obj, err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	interp := New(Options{})
+	calls := 0
+	err := interp.Register(&object.Builtin{
+		Name: "repeat",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			calls++
+			s, n := args[0].(*object.String), args[1].(*object.Int)
+			return object.NewString(strings.Repeat(s.Value, n.Value))
+		},
+		Info:    "Repeat a string n times",
+		ArgC:    2,
+		ArgT:    []string{"String", "Int"},
+		ReturnT: "String",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`repeat("ab", 2)`, "abab"},
+		{`try { repeat(2, 2) } catch e { string(e) }`,
+			"Argument 1 to function repeat not supported. Want=String. Got=Int."},
+		{`try { repeat("a") } catch e { string(e) }`,
+			"Wrong number of args to function repeat. Want=2. Got=1."},
+	}
+	for _, tt := range tests {
+		if got, err := interp.Eval(tt.input); err != nil || got != tt.expected {
+			t.Errorf("eval of %q. want=%q, got=%v, %v", tt.input, tt.expected, got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("builtin called with invalid arguments. calls=%d", calls)
+	}
+
+	nop := func(*object.Environment, ...object.Object) object.Object { return object.NONE }
+	invalid := []*object.Builtin{
+		{Name: "f"},
+		{Name: "f", Fn: nop, ArgC: 1, ArgT: []string{"Thing"}},
+		{Name: "f", Fn: nop, ArgC: 2, ArgT: []string{"Int"}},
+		{Name: "f", Fn: nop, ArgC: 2, ArgT: []string{"Int..."}},
+		{Name: "f", Fn: nop, ArgC: -1, ArgT: []string{"Any...", "Int"}},
+		{Name: "f", Fn: nop, ArgC: 2, ArgT: []string{"Int", "Int?"}},
+		{Name: "f", Fn: nop, ArgC: -1, ArgT: []string{"Int?", "Int"}},
+		{Name: "f", Fn: nop, ArgC: -1, ArgT: []string{"Int", "Thing?"}},
+	}
+	for _, builtin := range invalid {
+		if err := interp.Register(builtin); err == nil {
+			t.Errorf("expected error registering %+v", builtin)
+		}
+	}
+
+	if err := interp.Register(&object.Builtin{Name: "opt", Fn: nop, ArgC: -1, ArgT: []string{"Int", "Int?"}}); err != nil {
+		t.Fatal(err)
+	}
+	for input, expected := range map[string]string{
+		"opt(1)":      "",
+		"opt(1, 2)":   "",
+		"opt()":       "Wrong number of args to function opt. Want=1 to 2. Got=0.",
+		`opt(1, "a")`: "Argument 2 to function opt not supported. Want=Int. Got=String.",
+	} {
+		if _, err := interp.Eval(input); err == nil && expected != "" || err != nil && err.Error() != expected {
+			t.Errorf("eval of %q. want=%q, got=%v", input, expected, err)
+		}
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	interp := New(Options{})
+	if err := interp.Set("limit", int64(3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interp.Eval("let doubled = limit * 2"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := interp.Get("doubled"); !ok || got != 6 {
+		t.Errorf("wrong value of doubled. got=%v", got)
+	}
+	if _, ok := interp.Get("nothing"); ok {
+		t.Errorf("got a value for an undefined variable")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		limits   object.Limits
+		input    string
+		expected string
+	}{
+		{object.Limits{Steps: 1000}, "for true { }", "step limit of 1000 exceeded"},
+		{object.Limits{Depth: 50}, "def f(n) { f(n + 1) }; f(0)", "call depth limit of 50 exceeded"},
+		{object.Limits{Size: 1000}, `let mut s = "ab"; for true { s = s ++ s }`, "size limit of 1000 exceeded, got 1024"},
+		{object.Limits{Size: 1000}, "let a = [0] ++ range(0, 1000)", "size limit of 1000 exceeded, got 1001"},
+		{object.Limits{Size: 1000}, "array(1 << 40)", "size limit of 1000 exceeded, got 1099511627776"},
+		{object.Limits{Time: 20 * time.Millisecond}, "for true { }", "time limit of 20ms exceeded"},
+		{object.Limits{Time: 20 * time.Millisecond}, "sleep(10000)", "time limit of 20ms exceeded"},
+		{object.Limits{Time: 20 * time.Millisecond}, "sleep(2 ** 64)", "time limit of 20ms exceeded"},
+		{object.Limits{Time: 20 * time.Millisecond}, "sleep(10 ** 400)", "time limit of 20ms exceeded"},
+		{object.Limits{Size: 1000}, "len(array(iter(range(50000000))))", "size limit of 1000 exceeded, got 1001"},
+		{object.Limits{Time: 20 * time.Millisecond}, "len(array(iter(range(50000000))))", "time limit of 20ms exceeded"},
+		{object.Limits{Size: 1000}, "let d = {}; let mut i = 0; for true { d[i] = i; i += 1 }", "size limit of 1000 exceeded, got 1001"},
+		{object.Limits{Size: 1000}, "let mut x = 3; for true { x = x * x }", "size limit of 1000 exceeded, got 1623"},
+		{object.Limits{Time: 200 * time.Millisecond}, "let mut x = 3; for true { x = x * x }", "time limit of 200ms exceeded"},
+	}
+	for _, tt := range tests {
+		_, err := New(Options{Limits: tt.limits, Capabilities: object.CapTime}).Eval(tt.input)
+		if !errors.Is(err, ErrLimit) {
+			t.Errorf("eval of %q. expected a limit error, got=%v", tt.input, err)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("eval of %q. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestLimitsUncatchable(t *testing.T) {
+	interp := New(Options{Limits: object.Limits{Depth: 10, Steps: 10000}})
+	_, err := interp.Eval("def f(n) { f(n + 1) }; try { f(0) } catch e { [type(e), string(e)] }")
+	if !errors.Is(err, ErrLimit) || err.Error() != "call depth limit of 10 exceeded" {
+		t.Errorf("limit error caught. got=%v", err)
+	}
+	_, err = interp.Eval("for true { try { for true { } } catch { } }")
+	if !errors.Is(err, ErrLimit) {
+		t.Errorf("step limit escaped by catching it. got=%v", err)
+	}
+	timed := New(Options{Limits: object.Limits{Time: 50 * time.Millisecond}})
+	_, err = timed.Eval("let mut n = 0; for true { try { for true { n += 1 } } catch e { n += 1 } }")
+	if !errors.Is(err, ErrLimit) {
+		t.Errorf("time limit escaped by catching it. got=%v", err)
+	}
+	// each evaluation has its own budget.
+	if got, err := interp.Eval("let mut i = 0; for i < 100 { i += 1 }; i"); err != nil || got != 100 {
+		t.Errorf("limits not reset. got=%v, %v", got, err)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	interp := New(Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := interp.EvalContext(ctx, "for true { }"); !errors.Is(err, ErrLimit) ||
+		err.Error() != "evaluation cancelled: context canceled" {
+		t.Errorf("evaluation not cancelled. got=%v", err)
+	}
+	if _, err := interp.Eval("def spin() { for true { } }"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := interp.CallContext(ctx, "spin"); !errors.Is(err, ErrLimit) {
+		t.Errorf("call not cancelled. got=%v", err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	var out bytes.Buffer
+	sandbox := New(Options{Stdout: &out})
+	denied := []struct {
+		input    string
+		expected string
+	}{
+		{`open("x.txt", "w")`, "permission denied: open needs the filesystem capability"},
+		{`print("hi")`, "permission denied: print needs the stdio capability"},
+		{`write(STDOUT, "hi")`, "permission denied: STDOUT needs the stdio capability"},
+		{"sleep(1)", "permission denied: sleep needs the time capability"},
+		{"time()", "permission denied: time needs the time capability"},
+		{"random()", "permission denied: random needs the random capability"},
+		{"import std", "permission denied: import needs the filesystem capability"},
+		{"def f() { from missing import x }; f()", "permission denied: import needs the filesystem capability"},
+	}
+	for _, tt := range denied {
+		_, err := sandbox.Eval(tt.input)
+		if !errors.Is(err, ErrPermission) || err.Error() != tt.expected {
+			t.Errorf("eval of %q. want=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+	if _, err := sandbox.Eval(`try { open("x.txt", "r") } catch e { type(e) }`); !errors.Is(err, ErrPermission) {
+		t.Errorf("permission error caught. got=%v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("sandbox wrote output. got=%q", out.String())
+	}
+
+	allowed := New(Options{Stdout: &out, Capabilities: object.CapStdio | object.CapRandom})
+	if _, err := allowed.Eval(`print(random() < 1)`); err != nil || out.String() != "true\n" {
+		t.Errorf("allowed capabilities denied. got=%q, %v", out.String(), err)
+	}
+	if _, err := allowed.Eval("time()"); !errors.Is(err, ErrPermission) {
+		t.Errorf("time allowed without its capability. got=%v", err)
+	}
+}
+
+// randomOperands : values of every type, including the edge cases which
+// trip up the arithmetic and indexing of the interpreter.
+var randomOperands = []string{
+	"0", "1", "-1", "7", "9223372036854775807", "(-9223372036854775807 - 1)",
+	"2.5", "0.0", "-0.5", `"ab"`, `""`, `"ab"[0]`, `'é'`, `"héllo"`, "[]", `[1, "a", [2]]`,
+	`{"a": 1, 2: [3]}`, "{}", "range(5)", "range(0)", "range(5, 0, -2)",
+	"true", "false", "none", "len", "(def(x) -> x)", "P(1, 2)", "P",
+	"iter([1, 2])", `error("e")`, "f",
+}
+
+var randomOperators = []string{
+	"+", "-", "*", "/", "//", "%", "**", "==", "!=", "<", ">", "<=", ">=",
+	"<<", ">>", "++", "in", "and", "or",
+}
+
+var randomBuiltins = []string{
+	"int", "float", "string", "bool", "array", "error", "freeze", "traceback",
+	"type", "len", "abs", "sin", "tan", "cos", "iter", "next", "done",
+	"range", "ord", "chr",
+}
+
+// randomExpression : an expression of at most depth nested operations.
+func randomExpression(r *rand.Rand, depth int) string {
+	if depth == 0 {
+		return randomOperands[r.Intn(len(randomOperands))]
+	}
+	e := func() string { return randomExpression(r, depth-1) }
+	switch r.Intn(9) {
+	case 0:
+		return "(" + e() + " " + randomOperators[r.Intn(len(randomOperators))] + " " + e() + ")"
+	case 1:
+		return "(-" + e() + ")"
+	case 2:
+		return "(not " + e() + ")"
+	case 3:
+		return e() + "[" + e() + "]"
+	case 4:
+		return e() + "[" + e() + ":" + e() + "]"
+	case 5:
+		return randomBuiltins[r.Intn(len(randomBuiltins))] + "(" + e() + ")"
+	case 6:
+		return randomBuiltins[r.Intn(len(randomBuiltins))] + "(" + e() + ", " + e() + ")"
+	case 7:
+		return e() + "(" + e() + ")"
+	default:
+		return "(" + e() + ").x"
+	}
+}
+
+// randomProgram : a few statements built from random expressions.
+func randomProgram(r *rand.Rand) string {
+	var src strings.Builder
+	src.WriteString("struct P { x, y }\ndef f(a) {\n}\nlet none = f(0)\n")
+	e := func() string { return randomExpression(r, 1+r.Intn(3)) }
+	for i := r.Intn(4); i >= 0; i-- {
+		switch r.Intn(4) {
+		case 0:
+			op := []string{"=", "+=", "/=", "%="}[r.Intn(4)]
+			fmt.Fprintf(&src, "let mut v%d = %s\nv%d[%s] %s %s\n", i, e(), i, e(), op, e())
+		case 1:
+			fmt.Fprintf(&src, "for x in %s {\n%s\n}\n", e(), e())
+		case 2:
+			fmt.Fprintf(&src, "try {\n%s\n} catch e {\ntype(e)\n}\n", e())
+		default:
+			src.WriteString(e() + "\n")
+		}
+	}
+	return src.String()
+}
+
+// evalNoPanic : evaluate src in a fresh limited interpreter, failing the
+// test if the interpreter panics.
+func evalNoPanic(t *testing.T, src string) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("panic evaluating\n%s\n%v", src, r)
+		}
+	}()
+	interp := New(Options{Limits: object.Limits{Steps: 10000, Depth: 50, Size: 10000, Time: time.Second}})
+	interp.Eval(src)
+}
+
+// crashers : programs which have crashed the interpreter.
+var crashers = []string{
+	"7 // 0",
+	"7 % 0",
+	"-0.5 != range(5)",
+	"1 ++ {}",
+	"array(-1)",
+	`let s = "ab"; s[0] = ""`,
+	`"ab"[0] ** "ab"[1]`,
+	"def f() { }; f() + 1",
+	"let a = [0]; a[0] = a; a",
+	"let d = {}; d[1] = [d]; string(d)",
+	"struct P { x }; let p = P(0); p.x = [p]; [string(p), p]",
+}
+
+func TestRandomPrograms(t *testing.T) {
+	for _, src := range crashers {
+		evalNoPanic(t, src)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		evalNoPanic(t, randomProgram(r))
+	}
+}
+
+func TestInternalError(t *testing.T) {
+	interp := New(Options{})
+	interp.Register(&object.Builtin{
+		Name: "boom",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			var a []object.Object
+			return a[1]
+		},
+	})
+	_, err := interp.Eval("boom()")
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != object.InternalError ||
+		!strings.HasPrefix(e.Message, "internal error in builtin boom: runtime error: index out of range") {
+		t.Fatalf("panic not returned as an InternalError. got=%#v", err)
+	}
+	if !strings.Contains(e.Traceback, "line 1") {
+		t.Errorf("InternalError has no position. got=%q", e.Traceback)
+	}
+	got, err := interp.Eval("try { boom() } catch e { type(e) }")
+	if err != nil || got != "InternalError" {
+		t.Errorf("InternalError not caught. got=%v, %v", got, err)
+	}
+}
+
+func FuzzEval(f *testing.F) {
+	for _, src := range crashers {
+		f.Add(src)
+	}
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		f.Add(randomProgram(r))
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		evalNoPanic(t, src)
+	})
+}
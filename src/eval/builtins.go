@@ -2,10 +2,9 @@ package eval
 
 import (
 	"dito/src/object"
-	"io"
 	"math"
 	"math/rand"
-	"os"
+	"strings"
 	"time"
 )
 
@@ -29,6 +28,38 @@ var Builtins = map[string]*object.Builtin{
 		ArgT:    []string{"Atom"},
 		ReturnT: "Float",
 	},
+	"decimal": &object.Builtin{
+		Name:    "decimal",
+		Fn:      objectDecimal,
+		Info:    "Convert value to `Decimal`, `decimal(x)`, or round it to a number of places, `decimal(x, places)` or `decimal(x, places, rounding)` where rounding is one of \"half_even\", \"half_up\", \"down\", \"up\", \"floor\" or \"ceiling\".",
+		ArgC:    -1,
+		ArgT:    []string{"Atom", "Int?", "String?"},
+		ReturnT: "Decimal",
+	},
+	"rational": &object.Builtin{
+		Name:    "rational",
+		Fn:      objectRational,
+		Info:    "Convert value to `Rational`, `rational(x)`, or make the fraction `rational(numerator, denominator)`.",
+		ArgC:    -1,
+		ArgT:    []string{"Atom", "Atom?"},
+		ReturnT: "Rational",
+	},
+	"ord": &object.Builtin{
+		Name:    "ord",
+		Fn:      objectOrd,
+		Info:    "Return the unicode code point of a `Char`, or of a `String` of one character",
+		ArgC:    1,
+		ArgT:    []string{"Atom"},
+		ReturnT: "Int",
+	},
+	"chr": &object.Builtin{
+		Name:    "chr",
+		Fn:      typeSwitch(object.CharType),
+		Info:    "Return the `Char` of a unicode code point",
+		ArgC:    1,
+		ArgT:    []string{"Int"},
+		ReturnT: "Char",
+	},
 	"string": &object.Builtin{
 		Name:    "string",
 		Fn:      typeSwitch(object.StringType),
@@ -47,10 +78,9 @@ var Builtins = map[string]*object.Builtin{
 	},
 	"array": &object.Builtin{
 		Name:    "array",
-		Fn:      typeSwitch(object.ArrayType),
 		Info:    "Convert value to `Array`",
 		ArgC:    1,
-		ArgT:    []string{"Iter"},
+		ArgT:    []string{"Any"},
 		ReturnT: "Array",
 	},
 	"error": &object.Builtin{
@@ -61,6 +91,22 @@ var Builtins = map[string]*object.Builtin{
 		ArgT:    []string{"String"},
 		ReturnT: "Error",
 	},
+	"freeze": &object.Builtin{
+		Name:    "freeze",
+		Fn:      objectFreeze,
+		Info:    "Make a value and every value in it immutable, returning the value",
+		ArgC:    1,
+		ArgT:    []string{"Any"},
+		ReturnT: "Any",
+	},
+	"traceback": &object.Builtin{
+		Name:    "traceback",
+		Fn:      objectTraceback,
+		Info:    "Return the traceback of a caught `Exception`",
+		ArgC:    1,
+		ArgT:    []string{"Exception"},
+		ReturnT: "String",
+	},
 	"type": &object.Builtin{
 		Name:    "type",
 		Fn:      objectType,
@@ -82,7 +128,7 @@ var Builtins = map[string]*object.Builtin{
 		Fn:      objectAbs,
 		Info:    "Return the absolute value of an `Atom`",
 		ArgC:    1,
-		ArgT:    []string{"Atom"},
+		ArgT:    []string{"Numeric"},
 		ReturnT: "Atom",
 	},
 	"sin": &object.Builtin{
@@ -90,7 +136,7 @@ var Builtins = map[string]*object.Builtin{
 		Fn:      objectSin,
 		Info:    "Return the sine of x radians of an `Atom`",
 		ArgC:    1,
-		ArgT:    []string{"Atom"},
+		ArgT:    []string{"Numeric"},
 		ReturnT: "Float",
 	},
 	"tan": &object.Builtin{
@@ -98,7 +144,7 @@ var Builtins = map[string]*object.Builtin{
 		Fn:      objectTan,
 		Info:    "Return the tangent of x radians of an `Atom`",
 		ArgC:    1,
-		ArgT:    []string{"Atom"},
+		ArgT:    []string{"Numeric"},
 		ReturnT: "Float",
 	},
 	"cos": &object.Builtin{
@@ -106,56 +152,84 @@ var Builtins = map[string]*object.Builtin{
 		Fn:      objectCos,
 		Info:    "Return the cosine of x radians of an `Atom`",
 		ArgC:    1,
-		ArgT:    []string{"Atom"},
+		ArgT:    []string{"Numeric"},
 		ReturnT: "Float",
 	},
+	"iter": &object.Builtin{
+		Name:    "iter",
+		Info:    "Return an `Iterator` over the items of a value",
+		ArgC:    1,
+		ArgT:    []string{"Any"},
+		ReturnT: "Iterator",
+	},
+	"next": &object.Builtin{
+		Name:    "next",
+		Fn:      objectNext,
+		Info:    "Return the next item of an `Iterator`",
+		ArgC:    1,
+		ArgT:    []string{"Iterator"},
+		ReturnT: "Any",
+	},
+	"done": &object.Builtin{
+		Name:    "done",
+		Fn:      objectDone,
+		Info:    "Report whether every item of an `Iterator` has been returned",
+		ArgC:    1,
+		ArgT:    []string{"Iterator"},
+		ReturnT: "Bool",
+	},
 	"print": &object.Builtin{
-		Name:    "print",
-		Fn:      objectPrint,
-		Info:    "Print a variable number of arguments to the std out.",
-		ArgC:    -1,
-		ArgT:    []string{"Any..."},
-		ReturnT: "None",
+		Name:       "print",
+		Fn:         objectPrint,
+		Info:       "Print a variable number of arguments to the std out.",
+		ArgC:       -1,
+		ArgT:       []string{"Any..."},
+		ReturnT:    "None",
+		Capability: object.CapStdio,
 	},
 	"range": &object.Builtin{
 		Name:    "range",
 		Fn:      objectRange,
-		Info:    "Generate an Array of Int's within a given range.",
-		ArgC:    2,
-		ArgT:    []string{"Int", "Int"},
-		ReturnT: "Array",
+		Info:    "Return the Range of Int's from start up to stop counting by step, `range(stop)`, `range(start, stop)` or `range(start, stop, step)`.",
+		ArgC:    -1,
+		ArgT:    []string{"Int", "Int?", "Int?"},
+		ReturnT: "Range",
 	},
 	"random": &object.Builtin{
-		Name:    "random",
-		Fn:      objectRand,
-		Info:    "Generate a random Float between 0-1.",
-		ArgC:    0,
-		ArgT:    []string{},
-		ReturnT: "Float",
+		Name:       "random",
+		Fn:         objectRand,
+		Info:       "Generate a random Float between 0-1.",
+		ArgC:       0,
+		ArgT:       []string{},
+		ReturnT:    "Float",
+		Capability: object.CapRandom,
 	},
 	"time": &object.Builtin{
-		Name:    "time",
-		Fn:      objectTime,
-		Info:    "Return current Unix timestamp as an Int",
-		ArgC:    0,
-		ArgT:    []string{},
-		ReturnT: "Int",
+		Name:       "time",
+		Fn:         objectTime,
+		Info:       "Return current Unix timestamp as an Int",
+		ArgC:       0,
+		ArgT:       []string{},
+		ReturnT:    "Int",
+		Capability: object.CapTime,
 	},
 	"sleep": &object.Builtin{
-		Name:    "sleep",
-		Fn:      objectSleep,
-		Info:    "Pause execution for x milliseconds",
-		ArgC:    1,
-		ArgT:    []string{"Atom"},
-		ReturnT: "None",
+		Name:       "sleep",
+		Fn:         objectSleep,
+		Info:       "Pause execution for x milliseconds",
+		ArgC:       1,
+		ArgT:       []string{"Numeric"},
+		ReturnT:    "None",
+		Capability: object.CapTime,
 	},
 	"open": &object.Builtin{
-		Name:    "fopen",
-		Fn:      fileOpen,
-		Info:    "Open and return a file object",
-		ArgC:    1,
-		ArgT:    []string{"String"},
-		ReturnT: "None",
+		Name:       "open",
+		Fn:         fileOpen,
+		Info:       "Open a file for reading (\"r\") or writing (\"w\")",
+		ArgC:       2,
+		ArgT:       []string{"String", "String"},
+		ReturnT:    "File",
+		Capability: object.CapFilesystem,
 	},
 	"close": &object.Builtin{
 		Name:    "close",
@@ -168,7 +242,7 @@ var Builtins = map[string]*object.Builtin{
 	"read": &object.Builtin{
 		Name:    "read",
 		Fn:      fileRead,
-		Info:    "close a file object",
+		Info:    "Read the rest of a file object",
 		ArgC:    1,
 		ArgT:    []string{"File"},
 		ReturnT: "String",
@@ -176,223 +250,270 @@ var Builtins = map[string]*object.Builtin{
 	"write": &object.Builtin{
 		Name:    "write",
 		Fn:      fileWrite,
-		Info:    "close a file object",
+		Info:    "Write a value to a file object",
 		ArgC:    2,
-		ArgT:    []string{"File"},
-		ReturnT: "String",
+		ArgT:    []string{"File", "Any"},
+		ReturnT: "None",
 	},
 }
 
-func fileOpen(args ...object.Object) object.Object {
-	if n := len(args); n != 2 {
-		return object.NewError(object.InvalidArgLenError, "open", 2, n)
-	}
-	path, ok := args[0].(*object.String)
-	if !ok {
-		return object.NewError("Argument '%s' to func 'open' is invalid", args[0].Inspect())
-	}
-	mode, ok := args[1].(*object.String)
-	if !ok {
-		return object.NewError("Argument '%s' to func 'open' is invalid", args[1].Inspect())
+func init() {
+	// set here as they call back into the evaluator, which reads Builtins.
+	Builtins["array"].Fn = objectArray
+	Builtins["iter"].Fn = objectIter
+}
+
+// builtinsOf : the builtins code running in env can call.
+func builtinsOf(env *object.Environment) map[string]*object.Builtin {
+	if builtins := env.Runtime().Builtins; builtins != nil {
+		return builtins
 	}
-	switch mode.Value {
+	return Builtins
+}
+
+func fileOpen(env *object.Environment, args ...object.Object) object.Object {
+	path := args[0].(*object.String).Value
+	switch mode := args[1].(*object.String); mode.Value {
 	case "w":
-		return object.FILE.Create(path.Value)
+		return object.FILE.Create(path)
 	case "r":
-		return object.FILE.Open(path.Value)
+		return object.FILE.Open(path)
 	default:
-		return object.NewError("Argument '%s' to func 'fopen' is invalid. unknown file mode", args[1].Inspect())
+		return object.NewError("Argument '%s' to func 'open' is invalid. unknown file mode", mode.Value)
 	}
 }
 
-func fileRead(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "read", 2, n)
-	}
-	fp, ok := args[0].(*object.File)
-	if !ok {
-		return object.NewError("Invalid type to func 'read', want=File, got=%s", args[0].Type())
-	}
-	return fp.Read()
+func fileRead(env *object.Environment, args ...object.Object) object.Object {
+	return args[0].(*object.File).Read()
 }
 
-func fileClose(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "close", 2, n)
-	}
-	fp, ok := args[0].(*object.File)
-	if !ok {
-		return object.NewError("Invalid type to func 'close', want=File, got=%s", args[0].Type())
-	}
-	return fp.Close()
+func fileClose(env *object.Environment, args ...object.Object) object.Object {
+	return args[0].(*object.File).Close()
 }
 
-func fileWrite(args ...object.Object) object.Object {
-	if n := len(args); n != 2 {
-		return object.NewError(object.InvalidArgLenError, "write", 2, n)
-	}
-	fp, ok := args[0].(*object.File)
-	if !ok {
-		return object.NewError("Invalid type to func 'close', want=File, got=%s", args[0].Type())
-	}
+func fileWrite(env *object.Environment, args ...object.Object) object.Object {
 	str := args[1].ConvertType(object.StringType)
 	if str.Type() == object.ErrorType {
 		return str
 	}
-	return fp.Write(str.(*object.String).Value)
+	return args[0].(*object.File).Write(str.(*object.String).Value)
 }
 
 func typeSwitch(which object.TypeFlag) object.BuiltinFunction {
-	return func(args ...object.Object) object.Object {
-		n := len(args)
-		switch n {
-		case 1:
-			return args[0].ConvertType(which)
-		default:
-			return object.NewError(object.InvalidArgLenError, which.String(), 1, n)
-		}
+	return func(env *object.Environment, args ...object.Object) object.Object {
+		return args[0].ConvertType(which)
 	}
 }
 
-func objectType(args ...object.Object) object.Object {
-	if n := len(args); n > 1 {
-		return object.NewError(object.InvalidArgLenError, "type", 1, n)
+// objectOrd : the code point of a Char, or a String of exactly one Char.
+func objectOrd(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Char:
+		return arg.ConvertType(object.IntType)
+	case *object.String:
+		c := arg.ConvertType(object.CharType)
+		if c.Type() == object.ErrorType {
+			return object.NewError("ord expected a single character, got a String of length %s", arg.Length().Inspect())
+		}
+		return c.ConvertType(object.IntType)
 	}
-	return object.NewString(args[0].Type().String())
+	return object.NewError("ord expected a Char or String, got %s", args[0].Type())
 }
 
-func objectLen(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "len", 1, n)
+// objectDecimal : convert args[0] to a Decimal, rounded to args[1] places
+// by the rounding named by args[2] or that of the runtime.
+func objectDecimal(env *object.Environment, args ...object.Object) object.Object {
+	ctx := env.Runtime().Decimal
+	if len(args) == 3 {
+		name := args[2].(*object.String)
+		mode, ok := object.ParseRounding(name.Value)
+		if !ok {
+			return object.NewError("unknown rounding '%s'", name.Value)
+		}
+		ctx.Rounding = mode
 	}
-	iter, ok := args[0].(object.Iterable)
-	if ok {
-		return iter.Length()
+	places := -1
+	if len(args) > 1 {
+		n := args[1].(*object.Int)
+		if n.Value < 0 || n.IsBig() {
+			return object.NewError("number of places must be from 0 to %d, got %s", math.MaxInt, n.Inspect())
+		}
+		if err := env.Runtime().CheckSize(n.Value); err != nil {
+			return err
+		}
+		places, ctx.Places = n.Value, n.Value
 	}
-	return object.NewError("Argument '%s' to func 'len' is not a Iterable", args[0].Inspect())
-}
-
-func objectAbs(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "abs", 1, n)
+	var d object.Object
+	if r, ok := args[0].(*object.Rational); ok {
+		d = r.Decimal(ctx)
+	} else {
+		d = args[0].ConvertType(object.DecimalType)
 	}
-	num, ok := args[0].(object.Numeric)
-	if ok {
-		return num.Abs()
+	if places < 0 || isError(d) {
+		return d
 	}
-	return object.NewError("Argument '%s' to func 'abs' is not a Numeric", args[0].Inspect())
+	return d.(*object.Decimal).Round(places, ctx.Rounding)
 }
 
-func objectCos(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "cos", 1, n)
-	}
-	switch v := args[0].(type) {
-	case *object.Float:
-		return object.NewFloat(math.Cos(v.Value))
-	case *object.Int:
-		return object.NewFloat(math.Cos(float64(v.Value)))
-	default:
-		return object.NewError("Argument '%s' to func 'cos' is invalid", args[0].Inspect())
-	}
+// objectRational : convert args[0] to a Rational, divided by args[1] when
+// it is given.
+func objectRational(env *object.Environment, args ...object.Object) object.Object {
+	num := args[0].ConvertType(object.RationalType)
+	if isError(num) || len(args) == 1 {
+		return num
+	}
+	den := args[1].ConvertType(object.RationalType)
+	if isError(den) {
+		return den
+	}
+	return num.(*object.Rational).Quo(den.(*object.Rational))
 }
 
-func objectTan(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "tan", 1, n)
-	}
-	switch v := args[0].(type) {
-	case *object.Float:
-		return object.NewFloat(math.Tan(v.Value))
+func objectArray(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
 	case *object.Int:
-		return object.NewFloat(math.Tan(float64(v.Value)))
-	default:
-		return object.NewError("Argument '%s' to func 'tan' is invalid", args[0].Inspect())
+		if err := env.Runtime().CheckSize(arg.Value); err != nil {
+			return err
+		}
+	case *object.Range:
+		if err := env.Runtime().CheckSize(arg.Length().(*object.Int).Value); err != nil {
+			return err
+		}
+	case *object.Instance:
+		it := GetIterator(arg, env)
+		if isError(it) {
+			return it
+		}
+		return object.Collect(env.Runtime(), it.(object.Iterator))
+	case object.Iterator:
+		return object.Collect(env.Runtime(), arg)
 	}
+	return args[0].ConvertType(object.ArrayType)
 }
 
-func objectSin(args ...object.Object) object.Object {
-	if n := len(args); n != 1 {
-		return object.NewError(object.InvalidArgLenError, "sin", 1, n)
+func objectType(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Instance:
+		return object.NewString(arg.Struct.Name)
+	case *object.Exception:
+		if arg.Err.Kind != "" {
+			return object.NewString(arg.Err.Kind)
+		}
 	}
-	switch v := args[0].(type) {
-	case *object.Float:
-		return object.NewFloat(math.Sin(v.Value))
-	case *object.Int:
-		return object.NewFloat(math.Sin(float64(v.Value)))
-	default:
-		return object.NewError("Argument '%s' to func 'sin' is invalid", args[0].Inspect())
+	return object.NewString(args[0].Type().String())
+}
+
+func objectLen(env *object.Environment, args ...object.Object) object.Object {
+	return args[0].(object.Iterable).Length()
+}
+
+func objectAbs(env *object.Environment, args ...object.Object) object.Object {
+	return args[0].(object.Numeric).Abs()
+}
+
+// toFloat : the value of a Numeric argument as a float64.
+func toFloat(num object.Object) float64 {
+	return num.ConvertType(object.FloatType).(*object.Float).Value
+}
+
+func objectCos(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewFloat(math.Cos(toFloat(args[0])))
+}
+
+func objectTan(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewFloat(math.Tan(toFloat(args[0])))
+}
+
+func objectSin(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewFloat(math.Sin(toFloat(args[0])))
+}
+
+func objectIter(env *object.Environment, args ...object.Object) object.Object {
+	return GetIterator(args[0], env)
+}
+
+func objectNext(env *object.Environment, args ...object.Object) object.Object {
+	it := args[0].(object.Iterator)
+	if it.Done() {
+		return object.NewError("next called on an exhausted Iterator")
 	}
+	return it.Next()
+}
+
+func objectDone(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewBool(args[0].(object.Iterator).Done())
 }
 
-func objectPrint(args ...object.Object) object.Object {
+func objectPrint(env *object.Environment, args ...object.Object) object.Object {
+	var out strings.Builder
 	for i, arg := range args {
-		io.WriteString(os.Stdout, arg.Inspect())
+		out.WriteString(arg.Inspect())
 		if i < len(args)-1 {
-			io.WriteString(os.Stdout, " ")
+			out.WriteString(" ")
 		}
 	}
-	io.WriteString(os.Stdout, "\n")
-	return object.NONE
+	out.WriteString("\n")
+	return env.Runtime().Stdout.Write(out.String())
 }
 
-func objectError(args ...object.Object) object.Object {
-	if len(args) != 1 {
-		return object.NewError(object.InvalidArgLenError, "error", 2, len(args))
-	}
-	if args[0].Type() != object.StringType {
-		return object.NewError("Argument to `error` not supported, got %s", args[0].Type())
-	}
-	return object.NewError(args[0].Inspect())
+func objectError(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewError("%s", args[0].Inspect())
 }
 
-func objectRange(args ...object.Object) object.Object {
-	if len(args) != 2 {
-		return object.NewError(object.InvalidArgLenError, "range", 2, len(args))
-	}
-	min, ok := args[0].(*object.Int)
-	if !ok {
-		return object.NewError("Invalid args[0] to `range` function. got=%T", args[0])
-	}
-	max, ok := args[1].(*object.Int)
-	if !ok {
-		return object.NewError("Invalid args[1] to `range` function. got=%T", args[1])
-	}
-	if max.Value-min.Value < 0 {
-		return object.NewError("Invalid args to `range` function. a > b.")
+func objectFreeze(env *object.Environment, args ...object.Object) object.Object {
+	return object.Freeze(args[0])
+}
+
+func objectTraceback(env *object.Environment, args ...object.Object) object.Object {
+	return object.NewString(args[0].(*object.Exception).Err.Traceback())
+}
+
+func objectRange(env *object.Environment, args ...object.Object) object.Object {
+	bounds := make([]int, len(args))
+	for i, arg := range args {
+		if arg.(*object.Int).IsBig() {
+			return object.NewError("range bound %s too large", arg.Inspect())
+		}
+		bounds[i] = arg.(*object.Int).Value
+	}
+	var r *object.Range
+	switch len(bounds) {
+	case 1:
+		r = object.NewRange(0, bounds[0], 1)
+	case 2:
+		r = object.NewRange(bounds[0], bounds[1], 1)
+	case 3:
+		if bounds[2] == 0 {
+			return object.NewError("Invalid args to `range` function. step must not be 0.")
+		}
+		r = object.NewRange(bounds[0], bounds[1], bounds[2])
 	}
-	iter := make([]object.Object, max.Value-min.Value)
-	i := 0
-	for v := min.Value; v < max.Value; v++ {
-		iter[i] = object.NewInt(v)
-		i++
+	if !r.Fits() {
+		return object.NewError("%s has too many items", r.Inspect())
 	}
-	return object.NewArray(iter, -1)
+	return r
 }
 
-func objectRand(args ...object.Object) object.Object {
-	if len(args) != 0 {
-		return object.NewError(object.InvalidArgLenError, "random", 0, len(args))
-	}
+func objectRand(env *object.Environment, args ...object.Object) object.Object {
 	return object.NewFloat(rand.Float64())
 }
 
-func objectTime(args ...object.Object) object.Object {
-	if len(args) != 0 {
-		return object.NewError(object.InvalidArgLenError, "time", 0, len(args))
-	}
+func objectTime(env *object.Environment, args ...object.Object) object.Object {
 	return object.NewInt(int(time.Now().Unix()))
 }
 
-func objectSleep(args ...object.Object) object.Object {
-	switch arg := args[0].(type) {
-	case *object.Int:
-		time.Sleep(time.Duration(arg.Value) * time.Millisecond)
-		return object.NONE
-	case *object.Float:
-		time.Sleep(time.Duration(arg.Value) * time.Millisecond)
+func objectSleep(env *object.Environment, args ...object.Object) object.Object {
+	rt := env.Runtime()
+	// durations too long for a time.Duration would overflow, and not sleep at
+	// all, so they sleep for the longest one instead.
+	d := time.Duration(math.MaxInt64)
+	if ms := toFloat(args[0]) * float64(time.Millisecond); ms < float64(math.MaxInt64) {
+		d = time.Duration(ms)
+	}
+	select {
+	case <-time.After(d):
 		return object.NONE
-	default:
-		return object.NewError("Argument to `sleep` not supported, got %s", args[0].Type())
+	case <-rt.Context().Done():
+		return rt.Cancelled()
 	}
 }
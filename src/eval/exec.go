@@ -14,6 +14,21 @@ func init() {
 
 // Eval :
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	var result object.Object
+	if err := env.Runtime().Step(); err != nil {
+		result = err
+	} else {
+		result = evalNode(node, env)
+	}
+	if err, ok := result.(*object.Error); ok && !err.Traced() {
+		// the first node an error passes through in a function is where it
+		// was raised, or where the function it left was resumed from.
+		err.AddFrame(node.Pos())
+	}
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node.Statements, env)
@@ -25,8 +40,24 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalReAssign(node, env)
 	case *ast.IndexAssignmentStatement:
 		return evalIndexAssignment(node, env)
+	case *ast.AttributeAssignmentStatement:
+		return evalAttributeAssignment(node, env)
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
 	case *ast.ReturnStatement:
-		return &object.ReturnValue{Value: Eval(node.Value, env)}
+		val := evalTail(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.YieldStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return evalYield(val, env)
+	case *ast.NonlocalStatement:
+		return evalNonlocalStatement(node, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.IfStatement:
@@ -37,10 +68,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatement(node, env)
 	case *ast.ImportStatement:
 		return evalImportStatement(node, env)
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+	case *ast.RaiseStatement:
+		return evalRaiseStatement(node, env)
+	case *ast.BreakStatement:
+		return object.BREAK
+	case *ast.ContinueStatement:
+		return object.CONTINUE
 
 	// Expressions
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
 		return evalInfixExpression(node, env)
@@ -50,20 +92,31 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIndexExpression(node, env)
 	case *ast.SliceExpression:
 		return evalSliceExpression(node, env)
+	case *ast.AttributeExpression:
+		return evalAttributeExpression(node, env)
 	// // Functions
 	case *ast.Function:
-		return object.NewFunction(node, env)
+		fn := object.NewFunction(node, env)
+		if res := env.Declare(fn.Name, fn, false); isError(res) {
+			return res
+		}
+		return fn
 	case *ast.LambdaFunction:
 		return object.NewLambda(node.Parameters, node.Expr, env)
 	case *ast.CallExpression:
-		return evalFunctionCall(node.Function, node.Arguments, env)
+		return evalFunctionCall(node, env)
 
 	// Atoms
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.StringLiteral:
 		return object.NewString(node.Value)
+	case *ast.CharLiteral:
+		return object.NewChar(node.Value)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return object.NewBigInt(node.Big)
+		}
 		return object.NewInt(node.Value)
 	case *ast.FloatLiteral:
 		return object.NewFloat(node.Value)
@@ -73,6 +126,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	// Composite:
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
 		return object.NewArray(elements, -1)
 	case *ast.DictLiteral:
 		return evalDictExpression(node, env)
@@ -86,7 +142,7 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 		result = Eval(statement, env)
 		switch result := result.(type) {
 		case *object.ReturnValue:
-			return result.Value
+			return callTail(result.Value, env)
 		case *object.Error:
 			return result
 		}
@@ -98,9 +154,12 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
-	if builtin, ok := Builtins[node.Value]; ok {
+	if builtin, ok := builtinsOf(env)[node.Value]; ok {
 		return builtin
 	}
+	if _, ok := object.SystemVars[node.Value]; ok {
+		return object.NewPermissionError(node.Value, object.CapStdio)
+	}
 	return object.NewError("Identifier not found: '%s'", node.Value)
 }
 
@@ -113,6 +172,9 @@ func isError(obj object.Object) bool {
 
 // Infix expressions.
 func evalInfixExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	if node.Operator == "and" || node.Operator == "or" {
+		return evalLogical(node, env)
+	}
 	op := object.BinaryOps[node.Operator]
 	if op == nil {
 		return object.NewError("Unknown Binary operation: '%s'", node.Operator)
@@ -127,3 +189,14 @@ func evalInfixExpression(node *ast.InfixExpression, env *object.Environment) obj
 	}
 	return op.EvalBinary(env, left, right)
 }
+
+// evalLogical : the value of an and or or expression, which is the operand
+// deciding whether it is true. The right operand is only evaluated when the
+// left one does not decide it.
+func evalLogical(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) || isTrue(left) == (node.Operator == "or") {
+		return left
+	}
+	return Eval(node.Right, env)
+}
@@ -36,13 +36,15 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() == object.IntType {
-		value := right.(*object.Int).Value
-		return object.NewInt(-value)
-	}
-	if right.Type() == object.FloatType {
-		value := right.(*object.Float).Value
-		return object.NewFloat(-value)
+	switch right := right.(type) {
+	case *object.Int:
+		return right.Neg()
+	case *object.Float:
+		return object.NewFloat(-right.Value)
+	case *object.Decimal:
+		return right.Neg()
+	case *object.Rational:
+		return right.Neg()
 	}
 	return object.NewError("Unknown operator: -%s", right.Type())
 }
@@ -73,7 +75,17 @@ func evalExpressions(expr []ast.Expression, env *object.Environment) []object.Ob
 func evalDictExpression(dl *ast.DictLiteral, env *object.Environment) object.Object {
 	d := object.NewDict()
 	for key, value := range dl.Items {
-		d.SetItem(Eval(key, env), Eval(value, env))
+		k := Eval(key, env)
+		if isError(k) {
+			return k
+		}
+		v := Eval(value, env)
+		if isError(v) {
+			return v
+		}
+		if res := d.SetItem(k, v); isError(res) {
+			return res
+		}
 	}
 	return d
 }
@@ -5,16 +5,34 @@ import (
 	"dito/src/object"
 )
 
-func evalFunctionCall(fn ast.Expression, fnArgs []ast.Expression, env *object.Environment) object.Object {
-	function := Eval(fn, env)
+func evalFunctionCall(node *ast.CallExpression, env *object.Environment) object.Object {
+	function, args := evalCall(node, env)
 	if isError(function) {
 		return function
 	}
-	args := evalExpressions(fnArgs, env)
+	return applyCall(node, function, args, env)
+}
+
+// evalCall : evaluate the function and arguments of a call. If either fails
+// the Error is returned in place of the function.
+func evalCall(node *ast.CallExpression, env *object.Environment) (object.Object, []object.Object) {
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return function, nil
+	}
+	args := evalExpressions(node.Arguments, env)
 	if len(args) == 1 && isError(args[0]) {
-		return args[0]
+		return args[0], nil
 	}
-	return applyFunction(function, args)
+	return function, args
+}
+
+func applyCall(node *ast.CallExpression, function object.Object, args []object.Object, env *object.Environment) object.Object {
+	result := ApplyFunction(function, args, env)
+	if err, ok := result.(*object.Error); ok {
+		err.AddFrame(node.Pos())
+	}
+	return result
 }
 
 func extendLambdaEnv(fn *object.Lambda, args []object.Object) (*object.Environment, *object.Error) {
@@ -23,30 +41,145 @@ func extendLambdaEnv(fn *object.Lambda, args []object.Object) (*object.Environme
 		return nil, object.NewError("Wrong number of function args. Want=%d, Got=%d. in %s",
 			len(fn.Parameters), len(args), fn.Inspect())
 	}
+	if err := object.CheckArgTypes("<lambda>", fn.ParamTypes, args); err != nil {
+		return nil, err
+	}
 	for paramIdx, param := range fn.Parameters {
 		env.Set(param.Value, args[paramIdx], false)
 	}
 	return env, nil
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// evalTail : evaluate an expression in tail position. Calls of dito functions
+// are not made but returned as a TailCall for the function being evaluated to
+// make in its own place, so tail recursion runs in constant Go stack.
+func evalTail(node ast.Expression, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		if err := env.Runtime().Step(); err != nil {
+			err.AddFrame(node.Pos())
+			return err
+		}
+		function, args := evalCall(node, env)
+		if isError(function) {
+			return function
+		}
+		if bm, ok := function.(*object.BoundMethod); ok {
+			function, args = bm.Fn, bm.Args(args)
+		}
+		switch function.(type) {
+		case *object.Function, *object.Lambda:
+			return &object.TailCall{Fn: function, Args: args, Pos: node.Pos()}
+		}
+		return applyCall(node, function, args, env)
+	case *ast.IfElseExpression:
+		if node.Alternative == nil {
+			break
+		}
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if isTrue(condition) {
+			return evalTail(node.Initial, env)
+		}
+		return evalTail(node.Alternative, env)
+	}
+	return Eval(node, env)
+}
+
+// callTail : make the call if obj is a TailCall, otherwise return obj.
+func callTail(obj object.Object, env *object.Environment) object.Object {
+	tc, ok := obj.(*object.TailCall)
+	if !ok {
+		return obj
+	}
+	result := ApplyFunction(tc.Fn, tc.Args, env)
+	if err, ok := result.(*object.Error); ok {
+		err.AddFrame(tc.Pos)
+	}
+	return result
+}
+
+// ApplyFunction : call fn with args, env is the environment of the caller.
+// Tail calls made by dito functions are made in a loop here rather than by
+// recursion. Only the last of them is kept in the traceback of an error.
+func ApplyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	switch fn.(type) {
+	case *object.Lambda, *object.Function:
+		rt := env.Runtime()
+		if err := rt.Enter(); err != nil {
+			return err
+		}
+		defer rt.Leave()
+	}
+	var caller object.Object
+	var last *object.TailCall
+	for {
+		result := applyFunction(fn, args, env)
+		tc, ok := result.(*object.TailCall)
+		if !ok {
+			if err, ok := result.(*object.Error); ok && last != nil {
+				err.AddFrame(last.Pos)
+				err.NameFrames(functionName(caller))
+			}
+			return result
+		}
+		caller, last = fn, tc
+		fn, args = tc.Fn, tc.Args
+	}
+}
+
+func functionName(fn object.Object) string {
+	if f, ok := fn.(*object.Function); ok {
+		return f.Name
+	}
+	return "<lambda>"
+}
+
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Lambda:
 		extendedEnv, err := extendLambdaEnv(fn, args)
 		if err != nil {
 			return err
 		}
-		evaluated := Eval(fn.Expr, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		evaluated := evalTail(fn.Expr, extendedEnv)
+		return unwindFrames(unwrapReturnValue(evaluated), "<lambda>")
 	case *object.Function:
 		extendedEnv, err := extendFunctionEnv(fn, args)
 		if err != nil {
 			return err
 		}
-		evaluated := Eval(fn.Stmts, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		if fn.Generator {
+			g := newGenerator(fn, extendedEnv)
+			if err := object.CheckReturnType(fn.Name, fn.ReturnT, g); err != nil {
+				return err
+			}
+			return g
+		}
+		evaluated := unwrapReturnValue(Eval(fn.Stmts, extendedEnv))
+		if evaluated == nil {
+			// a body whose last statement has no value, like an empty one.
+			evaluated = object.NONE
+		}
+		if fn.ReturnT != "" && !isError(evaluated) {
+			// the value of a tail call is needed to check its type, so it is
+			// made here rather than in the loop of ApplyFunction.
+			evaluated = callTail(evaluated, extendedEnv)
+			if !isError(evaluated) {
+				if err := object.CheckReturnType(fn.Name, fn.ReturnT, evaluated); err != nil {
+					return err
+				}
+			}
+		}
+		return unwindFrames(evaluated, fn.Name)
 	case *object.Builtin:
-		return fn.Fn(args...)
+		return fn.Call(env, args)
+	case *object.BoundMethod:
+		return ApplyFunction(fn.Fn, fn.Args(args), env)
+	case *object.Struct:
+		return fn.New(args)
 	default:
 		return object.NewError("not a function: %s", fn.Type())
 	}
@@ -59,12 +192,23 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
+// unwindFrames : name the traceback frames of an error leaving a function.
+func unwindFrames(obj object.Object, function string) object.Object {
+	if err, ok := obj.(*object.Error); ok {
+		err.NameFrames(function)
+	}
+	return obj
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fn.Env)
 	if len(fn.Parameters) != len(args) {
 		return nil, object.NewError("Wrong number of function args. Want=%d, Got=%d.",
 			len(fn.Parameters), len(args))
 	}
+	if err := object.CheckArgTypes(fn.Name, fn.ParamTypes, args); err != nil {
+		return nil, err
+	}
 	for paramIdx, param := range fn.Parameters {
 		env.Set(param.Value, args[paramIdx], false)
 	}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"dito/src/object"
+	"iter"
+)
+
+// generator : the Iterator returned by calling a function which contains a
+// yield statement. Its body runs as a coroutine, suspended at each yield
+// until the next item is wanted, so items are only made when they are used.
+type generator struct {
+	name    string
+	rt      *object.Runtime
+	next    func() (object.Object, bool)
+	stop    func()
+	item    object.Object // the next item, nil until the body has yielded it.
+	done    bool
+	running bool
+}
+
+// newGenerator : return a generator which runs the body of fn in env. It is
+// stopped by a for in loop left early and when the evaluation finishes, so
+// its body is not kept suspended forever.
+func newGenerator(fn *object.Function, env *object.Environment) *generator {
+	body := func(yield func(object.Object) bool) {
+		env.SetYield(yield)
+		result := unwrapReturnValue(Eval(fn.Stmts, env))
+		if isError(result) {
+			yield(unwindFrames(result, fn.Name))
+			return
+		}
+		// a tail call in a return statement is still made for its effects.
+		if result = callTail(result, env); isError(result) {
+			yield(unwindFrames(result, fn.Name))
+		}
+	}
+	g := &generator{name: fn.Name, rt: env.Runtime()}
+	g.next, g.stop = iter.Pull(body)
+	g.rt.Suspend(g)
+	return g
+}
+
+// Stop : end the body where it is suspended, as if its yield statement
+// returned. A generator cannot be stopped by its own body.
+func (g *generator) Stop() {
+	if g.done || g.running {
+		return
+	}
+	g.done, g.item = true, nil
+	g.stop()
+	g.rt.Resumed(g)
+}
+
+// evalYield : the value of a yield statement, a ReturnValue ends the body
+// when the generator has been stopped.
+func evalYield(val object.Object, env *object.Environment) object.Object {
+	if !env.Yield(val) {
+		return &object.ReturnValue{Value: object.NONE}
+	}
+	return object.NONE
+}
+
+func (g *generator) Type() object.TypeFlag { return object.IteratorType }
+func (g *generator) Inspect() string       { return "<generator " + g.name + ">" }
+func (g *generator) ConvertType(which object.TypeFlag) object.Object {
+	if which == object.ArrayType {
+		return object.Collect(nil, g)
+	}
+	return object.NewError(object.ConvertTypeError, g.Type(), which)
+}
+
+// Done : run the body until it yields the next item or returns.
+func (g *generator) Done() bool {
+	if g.item != nil || g.done {
+		return g.item == nil
+	}
+	if g.running {
+		g.item = object.NewError("generator %s is already running", g.name)
+		return false
+	}
+	g.running = true
+	item, ok := g.next()
+	g.running = false
+	if !ok {
+		g.done = true
+		g.rt.Resumed(g)
+		return true
+	}
+	g.item = item
+	return false
+}
+
+// Next : return the item yielded, or the Error which ended the body.
+func (g *generator) Next() object.Object {
+	if g.Done() {
+		return object.NewError("next called on an exhausted Iterator")
+	}
+	item := g.item
+	g.item = nil
+	return item
+}
@@ -5,26 +5,135 @@ import (
 	"dito/src/object"
 	"dito/src/parser"
 	"dito/src/scanner"
+	"dito/src/token"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+// ResolveImport : return the path of the file for the module name imported
+// by the file from. The directory of from is searched before the ImportPath
+// of the runtime.
+func ResolveImport(rt *object.Runtime, name, from string) (string, error) {
+	dirs := append([]string{importDir(from)}, rt.ImportPath...)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+".dito")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return filepath.Abs(path)
+		}
+	}
+	return "", fmt.Errorf("no module named '%s' (searched %s)", name, strings.Join(dirs, ", "))
+}
+
+// CheckImport : return a PermissionError unless the runtime allows code to
+// read modules from the file system.
+func CheckImport(rt *object.Runtime) *object.Error {
+	if !rt.Allows(object.CapFilesystem) {
+		return object.NewPermissionError("import", object.CapFilesystem)
+	}
+	return nil
+}
+
+// importDir : the directory imports are relative to for a file. Input which
+// did not come from a file is relative to the working directory.
+func importDir(from string) string {
+	if from == "" || from == "-" || strings.HasPrefix(from, "<") {
+		return "."
+	}
+	return filepath.Dir(from)
+}
+
+// ParseModule : read and parse the file of a module.
+func ParseModule(path string) (*ast.Program, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("import file %s could not be opened", path)
+	}
+	p := parser.New(scanner.InitFile(path, string(file)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		var msg strings.Builder
+		p.PrintParseErrors(&msg, p.Errors())
+		return nil, fmt.Errorf("could not import file due to parse errors.\n%s", msg.String())
+	}
+	return program, nil
+}
+
+// ImportCycleError : returned when modules import each other, the chain of
+// files involved is given after the message.
+const ImportCycleError = "import cycle not allowed: %s"
+
+// FormatImportChain : join the files of an import cycle for an error.
+func FormatImportChain(chain []string) string {
+	names := make([]string, len(chain))
+	for i, path := range chain {
+		names[i] = filepath.Base(path)
+	}
+	return strings.Join(names, " -> ")
+}
+
 func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
-	filename := node.Value
-	filepath := "lib/" + filename + ".dito"
-	file, err := ioutil.ReadFile(filepath)
+	if err := CheckImport(env.Runtime()); err != nil {
+		return err
+	}
+	path, err := ResolveImport(env.Runtime(), node.Value, node.Pos().Filename())
+	if err != nil {
+		return object.NewError("%s", err)
+	}
+	modules := env.Runtime().Modules
+	module, chain := modules.Start(path)
+	if chain != nil {
+		return object.NewError(ImportCycleError, FormatImportChain(chain))
+	}
+	if module == nil {
+		loaded := loadModule(node.Value, path, env.Runtime())
+		if isError(loaded) {
+			return loaded
+		}
+		module = loaded.(*object.Module)
+	}
+	return bindImport(node, module, env)
+}
+
+// loadModule : evaluate the module at path in a new environment of the
+// runtime returning the Module or an Error.
+func loadModule(name, path string, rt *object.Runtime) object.Object {
+	var module *object.Module
+	defer func() { rt.Modules.Finish(path, module) }()
+	program, err := ParseModule(path)
 	if err != nil {
-		return object.NewError("Import File %s could not be opened.", filepath)
+		return object.NewError("%s", err)
 	}
-	il := scanner.Init(string(file))
-	ip := parser.New(il)
-	iprogram := ip.ParseProgram()
+	loaded := &object.Module{Name: name, Path: path, Env: object.RuntimeEnvironment(rt)}
+	if res := Eval(program, loaded.Env); isError(res) {
+		return unwindFrames(res, "<module>")
+	}
+	module = loaded
+	return module
+}
 
-	if len(ip.Errors()) != 0 {
-		ip.PrintParseErrors(os.Stderr, ip.Errors())
-		return object.NewError("Could not import file due to parse errors.")
+// bindImport : set the names an import statement introduces.
+func bindImport(node *ast.ImportStatement, module object.Attributable, env *object.Environment) object.Object {
+	if node.Token != token.FROM {
+		name := node.Value
+		if node.Alias != nil {
+			name = node.Alias.Value
+		}
+		if res := env.Declare(name, module, false); isError(res) {
+			return res
+		}
+		return nil
+	}
+	for _, n := range node.Names {
+		val := module.GetAttr(n.Value)
+		if isError(val) {
+			return object.NewError("cannot import name '%s' from '%s'", n.Value, node.Value)
+		}
+		if res := env.Declare(n.Value, val, false); isError(res) {
+			return res
+		}
 	}
-	Eval(iprogram, env)
-	// return object.NONE
 	return nil
 }
@@ -15,10 +15,7 @@ func evalIndexExpression(node *ast.IndexExpression, env *object.Environment) obj
 	if isError(key) {
 		return key
 	}
-	if iter, ok := left.(object.Iterable); ok {
-		return iter.GetItem(key)
-	}
-	return object.NewError("Item is not Iterable")
+	return object.GetItem(left, key)
 }
 
 func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
@@ -34,17 +31,10 @@ func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) obj
 	if isError(end) {
 		return end
 	}
-	if iter, ok := left.(object.Iterable); ok {
-		return iter.Slice(start, end)
-	}
-	return object.NewError("Item is not Iterable")
+	return object.Slice(left, start, end)
 }
 
 func evalIndexAssignment(node *ast.IndexAssignmentStatement, env *object.Environment) object.Object {
-	right := Eval(node.Value, env)
-	if isError(right) {
-		return right
-	}
 	maybeIter := Eval(node.IdxExp.Left, env)
 	if isError(maybeIter) {
 		return maybeIter
@@ -53,16 +43,24 @@ func evalIndexAssignment(node *ast.IndexAssignmentStatement, env *object.Environ
 	if isError(key) {
 		return key
 	}
-	iter, ok := maybeIter.(object.Iterable)
-	if !ok {
+	right := Eval(node.Value, env)
+	if isError(right) {
+		return right
+	}
+	if _, ok := maybeIter.(object.Iterable); !ok {
 		return object.NewError("Index assignment error: wrong type")
 	}
 	if node.Token == token.ASSIGN {
-		iter.SetItem(key, right)
+		if res := object.SetItem(env.Runtime(), maybeIter, key, right); isError(res) {
+			return res
+		}
 		return object.NONE
 	}
 	// TODO this is just implemented as a quick fix.
-	left := iter.GetItem(key)
+	left := object.GetItem(maybeIter, key)
+	if isError(left) {
+		return left
+	}
 	opString := node.Token.String()
 	op := object.BinaryOps[opString[:len(opString)-1]]
 	if op == nil {
@@ -72,7 +70,9 @@ func evalIndexAssignment(node *ast.IndexAssignmentStatement, env *object.Environ
 	if isError(val) {
 		return val
 	}
-	iter.SetItem(key, val)
+	if res := object.SetItem(env.Runtime(), maybeIter, key, val); isError(res) {
+		return res
+	}
 	return object.NONE
 }
 
@@ -85,24 +85,63 @@ func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Obje
 
 func evalForIn(fs *ast.ForStatement, env *object.Environment) object.Object {
 	var body object.Object
-	iter, ok := Eval(fs.Iter, env).(object.Iterable)
-	if !ok {
+	iterable := Eval(fs.Iter, env)
+	if isError(iterable) {
+		return iterable
+	}
+	iter := GetIterator(iterable, env)
+	if isError(iter) {
 		return iter
 	}
-	for item := range iter.Iter() {
-		env.Set(fs.ID.Value, item, false)
-		body = Eval(fs.LoopBody, env)
+	it := iter.(object.Iterator)
+	if g, ok := it.(*generator); ok && ownsIterator(fs, iterable, it) {
+		defer g.Stop()
+	}
+	for {
+		item := object.NextItem(it)
+		if item == nil {
+			break
+		}
+		if isError(item) {
+			return item
+		}
+		// each iteration has its own scope, so closures made in the body
+		// capture the item of their iteration.
+		bodyEnv := object.NewBlockEnvironment(env)
+		bodyEnv.Set(fs.ID.Value, item, false)
+		body = Eval(fs.LoopBody, bodyEnv)
 		if body != nil {
 			// the surrounding if is duplicated in isError fn.
 			rt := body.Type()
 			if rt == object.ErrorType || rt == object.ReturnType {
 				return body
 			}
+			if body == object.BREAK {
+				return object.NONE
+			}
 		}
 	}
+	if body == object.CONTINUE {
+		return object.NONE
+	}
 	return body
 }
 
+// ownsIterator : report whether the iterator of a for in loop can only be
+// reached by the loop, being made by it or by the expression it iterates
+// over, such as a call. A generator the loop owns is stopped when the loop is
+// left, otherwise it can still be resumed after a break.
+func ownsIterator(fs *ast.ForStatement, iterable, it object.Object) bool {
+	if it != iterable {
+		return true
+	}
+	switch fs.Iter.(type) {
+	case *ast.Identifier, *ast.AttributeExpression, *ast.IndexExpression:
+		return false
+	}
+	return true
+}
+
 func evalForWhile(fs *ast.ForStatement, env *object.Environment) object.Object {
 	var body, condition object.Object
 	for {
@@ -113,13 +152,19 @@ func evalForWhile(fs *ast.ForStatement, env *object.Environment) object.Object {
 		if isError(condition) {
 			return condition
 		}
-		body = Eval(fs.LoopBody, env)
+		body = Eval(fs.LoopBody, object.NewBlockEnvironment(env))
 		if body != nil {
 			rt := body.Type()
 			if rt == object.ErrorType || rt == object.ReturnType {
 				return body
 			}
+			if body == object.BREAK {
+				return object.NONE
+			}
 		}
 	}
+	if body == object.CONTINUE {
+		return object.NONE
+	}
 	return body
 }
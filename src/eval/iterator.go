@@ -0,0 +1,84 @@
+package eval
+
+import "dito/src/object"
+
+// GetIterator : return an Iterator over obj or an Error. Besides Iterables
+// and Iterators, instances of structs with an iter method are iterated by the
+// Iterator it returns and those with next and done methods are their own
+// Iterator.
+func GetIterator(obj object.Object, env *object.Environment) object.Object {
+	switch obj := obj.(type) {
+	case object.Iterator:
+		return obj
+	case object.Iterable:
+		return obj.Iter()
+	case *object.Instance:
+		if method, ok := obj.Struct.Methods["iter"]; ok {
+			it := callFunction(method, []object.Object{obj}, env)
+			if isError(it) {
+				return it
+			}
+			if inst, ok := it.(*object.Instance); ok {
+				return newInstanceIterator(inst, env)
+			}
+			return GetIterator(it, env)
+		}
+		return newInstanceIterator(obj, env)
+	}
+	return object.NewError("'%s' is not iterable", obj.Type())
+}
+
+// callFunction : call fn for a builtin, through the backend running the code.
+func callFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	if call := env.Runtime().Call; call != nil {
+		return call(fn, args)
+	}
+	return ApplyFunction(fn, args, env)
+}
+
+// instanceIterator : the Iterator of an instance with next and done methods.
+// An Error from done is returned by the following call of Next.
+type instanceIterator struct {
+	inst *object.Instance
+	next object.Object
+	done object.Object
+	env  *object.Environment
+	err  object.Object
+}
+
+func newInstanceIterator(inst *object.Instance, env *object.Environment) object.Object {
+	next, done := inst.Struct.Methods["next"], inst.Struct.Methods["done"]
+	if next == nil || done == nil {
+		return object.NewError("'%s' is not iterable, it needs an iter method or next and done methods", inst.Struct.Name)
+	}
+	return &instanceIterator{inst: inst, next: next, done: done, env: env}
+}
+
+func (it *instanceIterator) Type() object.TypeFlag { return object.IteratorType }
+func (it *instanceIterator) Inspect() string       { return "<iterator " + it.inst.Inspect() + ">" }
+func (it *instanceIterator) ConvertType(which object.TypeFlag) object.Object {
+	if which == object.ArrayType {
+		return object.Collect(it.env.Runtime(), it)
+	}
+	return object.NewError(object.ConvertTypeError, it.Type(), which)
+}
+
+func (it *instanceIterator) Done() bool {
+	if it.err != nil {
+		return false
+	}
+	done := callFunction(it.done, []object.Object{it.inst}, it.env)
+	if isError(done) {
+		it.err = done
+		return false
+	}
+	return isTrue(done)
+}
+
+func (it *instanceIterator) Next() object.Object {
+	if err := it.err; err != nil {
+		it.err = nil
+		return err
+	}
+	return callFunction(it.next, []object.Object{it.inst}, it.env)
+}
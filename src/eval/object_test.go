@@ -39,7 +39,7 @@ func TestIntExpr(t *testing.T) {
 		{"0xFFFFCDDE21 * 2", 2199016684610},
 		{"0x0001 << 0x003f - 1", 9223372036854775807},
 		{"1 << 63 - 1", 9223372036854775807},
-		{"1 << 63", -9223372036854775808},
+		{"-(1 << 63)", -9223372036854775808},
 		{"9223372036854775807 >> 63", 0},
 		{"9223372036854775807 >> 32", 2147483647},
 	}
@@ -145,3 +145,81 @@ let a = 2
 	}
 
 }
+
+func TestErrorTraceback(t *testing.T) {
+	input := `def inner(x) {
+    x + "a"
+}
+let outer = def(x) -> inner(x)
+outer(1)`
+	evaluated := testEval(t, input)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("eval didn't produce an error. got=%T", evaluated)
+	}
+	expected := []struct {
+		function string
+		line     int
+		column   int
+	}{
+		{"inner", 2, 5},
+		{"<lambda>", 4, 23},
+		{"", 5, 1},
+	}
+	if len(err.Trace) != len(expected) {
+		t.Fatalf("wrong number of frames. want=%d, got=%d", len(expected), len(err.Trace))
+	}
+	for i, want := range expected {
+		frame := err.Trace[i]
+		if frame.Function != want.function ||
+			frame.Pos.Line != want.line || frame.Pos.Column != want.column {
+			t.Errorf("frame %d wrong. want=%+v, got=%s in %q",
+				i, want, frame.Pos, frame.Function)
+		}
+	}
+}
+
+func TestBuiltinsValid(t *testing.T) {
+	for name, builtin := range Builtins {
+		if err := builtin.Validate(); err != nil {
+			t.Errorf("builtin %s is invalid: %s", name, err)
+		}
+		if builtin.Name != name {
+			t.Errorf("builtin %s has the name %s", name, builtin.Name)
+		}
+	}
+}
+
+func TestScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let mut fs = []; for i in [1, 2, 3] { fs = fs ++ [def() -> i] }; fs[0]() + fs[2]()", "4"},
+		{"let mut n = 0; def inc() { nonlocal n; n += 1 }; inc(); inc(); n", "2"},
+		{"def f() { let mut n = 1; def g() { nonlocal n; n = 2 }; g(); n }; f()", "2"},
+		{"let mut n = 0; def f() { n = 1 }; f()", "cannot assign to 'n' of an enclosing scope, declare it nonlocal"},
+		{"def f() { nonlocal missing }; f()", "no binding for nonlocal 'missing' found"},
+		{"let mut x = 1; for i in [1] { x += i; let mut x = 10; x += 1 }; x", "2"},
+		{"if true { let y = 1 }; y", "Identifier not found: 'y'"},
+		{"let mut s = 0; let mut i = 0; for i < 3 { let j = i; s += j; i += 1 }; s", "3"},
+		{"let x = 1; let x = 2", "identifier 'x' is already declared in this scope"},
+		{"let mut x = 1; def x() { 1 }", "identifier 'x' is already declared in this scope"},
+		{"def f(a) { let a = 1 }; f(0)", "identifier 'a' is already declared in this scope"},
+		{"for i in [1] { let i = 2 }", "identifier 'i' is already declared in this scope"},
+		{"struct P { x }; struct P { y }", "identifier 'P' is already declared in this scope"},
+		{"struct P { x, y, x }", "duplicate field 'x' in struct P"},
+		{"struct P { x; def x(self) { 1 } }", "duplicate name 'x' for a method of struct P"},
+		{"struct P { x; def m(self) { 1 }; def m(self) { 2 } }", "duplicate name 'm' for a method of struct P"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		got := evaluated.Inspect()
+		if err, ok := evaluated.(*object.Error); ok {
+			got = err.Message
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
@@ -11,7 +11,14 @@ func evalAssignment(node *ast.AssignmentStatement, env *object.Environment) obje
 	if isError(val) {
 		return val
 	}
-	env.Set(node.Name.Value, val, node.Token != token.LET)
+	typ := node.Name.TypeName()
+	if err := object.CheckVarType(node.Name.Value, typ, val); err != nil {
+		return err
+	}
+	if res := env.Declare(node.Name.Value, val, node.Token != token.LET); isError(res) {
+		return res
+	}
+	env.Annotate(node.Name.Value, typ)
 	return nil
 }
 
@@ -23,13 +30,20 @@ func evalReAssign(node *ast.ReAssignStatement, env *object.Environment) object.O
 	if !v.IsMutable() {
 		return object.NewError("identifier '%s' has a immutable value.", node.Name.Value)
 	}
+	scope, err := env.Target(node.Name.Value)
+	if err != nil {
+		return err
+	}
 	right := Eval(node.Value, env)
 	if isError(right) {
 		return right
 	}
 	// check to see if we are just doing a simple assignment.
 	if node.Token == token.ASSIGN {
-		env.Set(node.Name.Value, right, true)
+		if err := object.CheckVarType(node.Name.Value, v.TypeName(), right); err != nil {
+			return err
+		}
+		scope.Set(node.Name.Value, right, true)
 		return nil
 	}
 
@@ -46,7 +60,19 @@ func evalReAssign(node *ast.ReAssignStatement, env *object.Environment) object.O
 	if isError(val) {
 		return val
 	}
-	env.Set(node.Name.Value, val, true)
+	if err := object.CheckVarType(node.Name.Value, v.TypeName(), val); err != nil {
+		return err
+	}
+	scope.Set(node.Name.Value, val, true)
+	return nil
+}
+
+func evalNonlocalStatement(node *ast.NonlocalStatement, env *object.Environment) object.Object {
+	for _, name := range node.Names {
+		if err := env.Nonlocal(name.Value); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -56,7 +82,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		result = Eval(statement, env)
 		if result != nil {
 			rt := result.Type()
-			if rt == object.ErrorType || rt == object.ReturnType {
+			if rt == object.ErrorType || rt == object.ReturnType || rt == object.LoopControlType {
 				return result
 			}
 		}
@@ -70,10 +96,40 @@ func evalIfStatement(ie *ast.IfStatement, env *object.Environment) object.Object
 		return condition
 	}
 	if isTrue(condition) {
-		return Eval(ie.Consequence, env)
+		return Eval(ie.Consequence, object.NewBlockEnvironment(env))
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return Eval(ie.Alternative, object.NewBlockEnvironment(env))
 	} else {
 		return object.NONE
 	}
 }
+
+func evalTryStatement(node *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(node.Body, object.NewBlockEnvironment(env))
+	if rv, ok := result.(*object.ReturnValue); ok {
+		// a call returned from the body can still raise an error to catch.
+		if val := callTail(rv.Value, env); val != rv.Value {
+			result = &object.ReturnValue{Value: val}
+			if isError(val) {
+				result = val
+			}
+		}
+	}
+	err, ok := result.(*object.Error)
+	if !ok || err.Uncatchable() {
+		return result
+	}
+	handlerEnv := object.NewBlockEnvironment(env)
+	if node.Name != nil {
+		handlerEnv.Set(node.Name.Value, &object.Exception{Err: err}, false)
+	}
+	return Eval(node.Handler, handlerEnv)
+}
+
+func evalRaiseStatement(node *ast.RaiseStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	return object.Raise(val)
+}
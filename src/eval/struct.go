@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"dito/src/ast"
+	"dito/src/object"
+	"dito/src/token"
+)
+
+// StructFields : return the field names of a struct statement, or an Error
+// when a name is used by more than one of its fields and methods.
+func StructFields(node *ast.StructStatement) ([]string, *object.Error) {
+	fields := make([]string, len(node.Fields))
+	used := make(map[string]bool, len(node.Fields)+len(node.Methods))
+	for i, f := range node.Fields {
+		if used[f.Value] {
+			return nil, object.NewError("duplicate field '%s' in struct %s", f.Value, node.Name.Value)
+		}
+		used[f.Value] = true
+		fields[i] = f.Value
+	}
+	for _, m := range node.Methods {
+		if used[m.Name.Value] {
+			return nil, object.NewError("duplicate name '%s' for a method of struct %s", m.Name.Value, node.Name.Value)
+		}
+		used[m.Name.Value] = true
+	}
+	return fields, nil
+}
+
+func evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	fields, err := StructFields(node)
+	if err != nil {
+		return err
+	}
+	st := object.NewStruct(node.Name.Value, fields)
+	for _, m := range node.Methods {
+		// methods are only reachable through an instance so unlike other
+		// functions they are not set in the environment.
+		st.Methods[m.Name.Value] = object.NewFunction(m, env)
+	}
+	if res := env.Declare(st.Name, st, false); isError(res) {
+		return res
+	}
+	return st
+}
+
+func evalAttributeExpression(node *ast.AttributeExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	if attr, ok := left.(object.Attributable); ok {
+		return attr.GetAttr(node.Name.Value)
+	}
+	return object.NewError("'%s' has no attribute '%s'", left.Type(), node.Name.Value)
+}
+
+func evalAttributeAssignment(node *ast.AttributeAssignmentStatement, env *object.Environment) object.Object {
+	left := Eval(node.AttrExp.Left, env)
+	if isError(left) {
+		return left
+	}
+	right := Eval(node.Value, env)
+	if isError(right) {
+		return right
+	}
+	name := node.AttrExp.Name.Value
+	attr, ok := left.(object.Attributable)
+	if !ok {
+		return object.NewError("'%s' has no attribute '%s'", left.Type(), name)
+	}
+	if node.Token != token.ASSIGN {
+		opString := node.Token.String()
+		op := object.BinaryOps[opString[:len(opString)-1]]
+		if op == nil {
+			return object.NewError("Unknown in place binary op: '%s'", opString)
+		}
+		current := attr.GetAttr(name)
+		if isError(current) {
+			return current
+		}
+		right = op.EvalBinary(env, current, right)
+		if isError(right) {
+			return right
+		}
+	}
+	if res := attr.SetAttr(name, right); isError(res) {
+		return res
+	}
+	return object.NONE
+}
@@ -0,0 +1,73 @@
+package object
+
+import "dito/src/ast"
+
+// IsType : report whether obj has the type named by an annotation. Besides
+// the argument types understood in the ArgT of a Builtin, the name of a
+// Struct is the type of its Instances.
+func IsType(obj Object, name string) bool {
+	if match, ok := argTypes[name]; ok {
+		return match(obj)
+	}
+	inst, ok := obj.(*Instance)
+	return ok && inst.Struct.Name == name
+}
+
+// KnownType : report whether name is one of the argument types of a Builtin,
+// so it is a type whatever the program defines.
+func KnownType(name string) bool {
+	_, ok := argTypes[name]
+	return ok
+}
+
+// TypeName : the name of the type of obj as used by annotations, which is
+// the name of its Struct for an Instance.
+func TypeName(obj Object) string {
+	if inst, ok := obj.(*Instance); ok {
+		return inst.Struct.Name
+	}
+	return obj.Type().String()
+}
+
+// ParamTypes : the annotated types of params, nil when none are annotated.
+func ParamTypes(params []*ast.Identifier) []string {
+	var types []string
+	for i, p := range params {
+		if p.Type != nil {
+			if types == nil {
+				types = make([]string, len(params))
+			}
+			types[i] = p.Type.Value
+		}
+	}
+	return types
+}
+
+// CheckArgTypes : return an Error when one of args does not have the type
+// annotated for its parameter by types, see ParamTypes.
+func CheckArgTypes(name string, types []string, args []Object) *Error {
+	for i, want := range types {
+		if want != "" && i < len(args) && !IsType(args[i], want) {
+			return NewError(ArgTypeError, i+1, name, want, TypeName(args[i]))
+		}
+	}
+	return nil
+}
+
+// CheckReturnType : return an Error when the value returned by the function
+// name does not have the type want, which is empty when not annotated.
+func CheckReturnType(name, want string, val Object) *Error {
+	if want != "" && !IsType(val, want) {
+		return NewError(ReturnTypeError, name, want, TypeName(val))
+	}
+	return nil
+}
+
+// CheckVarType : return an Error when val can not be assigned to the
+// variable name annotated with the type want.
+func CheckVarType(name, want string, val Object) *Error {
+	if want != "" && !IsType(val, want) {
+		return NewError(VarTypeError, name, want, TypeName(val))
+	}
+	return nil
+}
@@ -12,20 +12,25 @@ import (
 type Array struct {
 	Elements []Object
 	Len      int
+	Frozen   bool
 }
 
 // Type : return objects type as a TypeFlag
 func (a *Array) Type() TypeFlag { return ArrayType }
 
 // Inspect : return a string representation of the objects value.
-func (a *Array) Inspect() string {
+func (a *Array) Inspect() string { return inspectIn(a, map[Object]bool{}) }
+
+func (a *Array) inspect(seen map[Object]bool) string {
 	var out bytes.Buffer
 	out.WriteString("[")
 	for i, el := range a.Elements {
 		if el.Type() == StringType {
 			out.WriteString("\"" + el.Inspect() + "\"")
+		} else if el.Type() == CharType {
+			out.WriteString("'" + el.Inspect() + "'")
 		} else {
-			out.WriteString(el.Inspect())
+			out.WriteString(inspectIn(el, seen))
 		}
 		if i < len(a.Elements)-1 {
 			out.WriteString(", ")
@@ -70,6 +75,9 @@ func (a *Array) GetItem(key Object) Object {
 
 // SetItem : set item at index except char or a string.
 func (a *Array) SetItem(key Object, val Object) Object {
+	if a.Frozen {
+		return frozenError(a.Type().String())
+	}
 	idx, ok := key.(*Int)
 	if !ok {
 		return NewError("index error")
@@ -101,12 +109,21 @@ func (a *Array) Slice(start Object, end Object) Object {
 		return NewError("slice start index out of bounds error")
 	}
 	slice := a.Elements[startInt.Value:endInt.Value]
-	return NewArray(slice, len(slice))
+	arr := NewArray(slice, len(slice))
+	// the slice shares the elements of the array, so is frozen with it.
+	arr.Frozen = a.Frozen
+	return arr
 }
 
-// Concat : Add item to the current string creating a new string.
+// Concat : return a new array of the elements of both arrays.
 func (a *Array) Concat(other Object) Object {
-	return NewArray(append(a.Elements, other.(*Array).Elements...), -1)
+	o, ok := other.(*Array)
+	if !ok {
+		return NewError(ConcatTypeError, a.Type(), other.Type())
+	}
+	elements := make([]Object, 0, len(a.Elements)+len(o.Elements))
+	elements = append(elements, a.Elements...)
+	return NewArray(append(elements, o.Elements...), -1)
 }
 
 // Contains :
@@ -131,14 +148,5 @@ func (a *Array) Contains(item Object) Object {
 	return FALSE
 }
 
-// Iter : loop through items elements in order.
-func (a *Array) Iter() <-chan Object {
-	ch := make(chan Object)
-	go func() {
-		for _, item := range a.Elements {
-			ch <- item
-		}
-		close(ch)
-	}()
-	return ch
-}
+// Iter : return an Iterator over the elements in order.
+func (a *Array) Iter() Iterator { return NewSliceIterator(a.Elements) }
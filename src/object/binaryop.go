@@ -3,6 +3,7 @@ package object
 import (
 	"dito/src/token"
 	"math"
+	"math/big"
 )
 
 type binaryFn func(*Environment, Object, Object) Object
@@ -23,6 +24,13 @@ func (op *binaryOp) whichType(t1, t2 TypeFlag) TypeFlag {
 	if t2 == ArrayType {
 		return ArrayType
 	}
+	if t2 == RangeType {
+		return RangeType
+	}
+	// a Char is a String of one character.
+	if t1 == CharType && t2 == StringType || t1 == StringType && t2 == CharType {
+		return StringType
+	}
 	if t1 > BoolType || t2 > BoolType {
 		return ErrorType
 	}
@@ -32,27 +40,9 @@ func (op *binaryOp) whichType(t1, t2 TypeFlag) TypeFlag {
 	return t2
 }
 
-func (op *binaryOp) EvalBinary(env *Environment, a, b Object) Object {
-
-	if op.token == token.AND || op.token == token.OR {
-		which := BoolType
-		fn := op.fn[which]
-		if fn == nil {
-			return NewError("unknown binary function for given types")
-		}
-		a = a.ConvertType(BoolType)
-		b = b.ConvertType(BoolType)
-		if a.Type() == ErrorType {
-			return NewError("a: cannot convert types: %s, %s (%s)",
-				a.Type(), which, a.Inspect())
-		}
-		if b.Type() == ErrorType {
-			return NewError("b: cannot convert types: %s, %s (%s)",
-				b.Type(), which, b.Inspect())
-		}
-		return fn(env, a, b)
-	}
-
+// EvalBinary : apply the operator to a and b, a panic in its function is
+// returned as an InternalError.
+func (op *binaryOp) EvalBinary(env *Environment, a, b Object) (result Object) {
 	//TODO: this needs cleaning up
 
 	which := op.whichType(a.Type(), b.Type())
@@ -60,7 +50,7 @@ func (op *binaryOp) EvalBinary(env *Environment, a, b Object) Object {
 	if which == ErrorType {
 		return NewError("mis matched types: %s, %s", a.Type(), b.Type())
 	}
-	if which != ArrayType && which != DictType {
+	if which != ArrayType && which != DictType && which != RangeType {
 		a = a.ConvertType(which)
 		b = b.ConvertType(which)
 		if a.Type() == ErrorType {
@@ -77,6 +67,7 @@ func (op *binaryOp) EvalBinary(env *Environment, a, b Object) Object {
 		return NewError("unknown binary function for given types: %s %s %s",
 			a.Type(), op.name, b.Type())
 	}
+	defer Recover(&result, "operator", op.name)
 	return fn(env, a, b)
 }
 
@@ -93,11 +84,17 @@ func init() {
 					return NewChar(a.(*Char).Value + b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value + b.(*Int).Value)
+					return a.(*Int).Add(b.(*Int))
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewFloat(a.(*Float).Value + b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).Add(b.(*Decimal))
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).Add(b.(*Rational))
+				},
 			},
 		},
 
@@ -108,11 +105,17 @@ func init() {
 					return NewChar(a.(*Char).Value - b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value - b.(*Int).Value)
+					return a.(*Int).Sub(b.(*Int))
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewFloat(a.(*Float).Value - b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).Sub(b.(*Decimal))
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).Sub(b.(*Rational))
+				},
 			},
 		},
 
@@ -123,11 +126,23 @@ func init() {
 					return NewChar(a.(*Char).Value * b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value * b.(*Int).Value)
+					x, y := a.(*Int), b.(*Int)
+					if x.IsBig() || y.IsBig() {
+						if err := checkBig(env, (x.big().BitLen()+y.big().BitLen())/8); err != nil {
+							return err
+						}
+					}
+					return x.Mul(y)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewFloat(a.(*Float).Value * b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).Mul(b.(*Decimal), env.Runtime().Decimal)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).Mul(b.(*Rational))
+				},
 			},
 		},
 
@@ -138,11 +153,21 @@ func init() {
 					return NewFloat(float64(a.(*Char).Value) / float64(b.(*Char).Value))
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewFloat(float64(a.(*Int).Value) / float64(b.(*Int).Value))
+					x, y := a.(*Int), b.(*Int)
+					if x.IsBig() || y.IsBig() {
+						return NewFloat(bigQuotient(x, y))
+					}
+					return NewFloat(float64(x.Value) / float64(y.Value))
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewFloat(a.(*Float).Value / b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).Quo(b.(*Decimal), env.Runtime().Decimal)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).Quo(b.(*Rational))
+				},
 			},
 		},
 
@@ -150,10 +175,22 @@ func init() {
 			name: "%",
 			fn: map[TypeFlag]binaryFn{
 				CharType: func(env *Environment, a, b Object) Object {
+					if b.(*Char).Value == 0 {
+						return NewError(DivisionByZeroError)
+					}
 					return NewChar(a.(*Char).Value % b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value % b.(*Int).Value)
+					if b.(*Int).Value == 0 {
+						return NewError(DivisionByZeroError)
+					}
+					return a.(*Int).Rem(b.(*Int))
+				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).Rem(b.(*Decimal))
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).Rem(b.(*Rational))
 				},
 			},
 		},
@@ -162,10 +199,22 @@ func init() {
 			name: "//",
 			fn: map[TypeFlag]binaryFn{
 				CharType: func(env *Environment, a, b Object) Object {
+					if b.(*Char).Value == 0 {
+						return NewError(DivisionByZeroError)
+					}
 					return NewChar(a.(*Char).Value / b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value / b.(*Int).Value)
+					if b.(*Int).Value == 0 {
+						return NewError(DivisionByZeroError)
+					}
+					return a.(*Int).Quo(b.(*Int))
+				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return a.(*Decimal).IntQuo(b.(*Decimal))
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return a.(*Rational).IntQuo(b.(*Rational))
 				},
 			},
 		},
@@ -174,17 +223,34 @@ func init() {
 			name: "**",
 			fn: map[TypeFlag]binaryFn{
 				CharType: func(env *Environment, a, b Object) Object {
-					v := math.Pow(float64(a.(*Int).Value), float64(b.(*Int).Value))
-					return NewInt(int(v))
+					v := math.Pow(float64(a.(*Char).Value), float64(b.(*Char).Value))
+					return NewChar(rune(v))
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					v := math.Pow(float64(a.(*Int).Value), float64(b.(*Int).Value))
-					return NewInt(int(v))
+					x, y := a.(*Int), b.(*Int)
+					if err := checkBig(env, x.bits(y, true)/8); err != nil {
+						return err
+					}
+					return x.Pow(y)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					v := math.Pow(a.(*Float).Value, b.(*Float).Value)
 					return NewFloat(v)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					x, y := a.(*Decimal), b.(*Decimal)
+					if err := env.Runtime().CheckSize(x.bits(y) / 8); err != nil {
+						return err
+					}
+					return x.Pow(y, env.Runtime().Decimal)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					x, y := a.(*Rational), b.(*Rational)
+					if err := env.Runtime().CheckSize(x.bits(y) / 8); err != nil {
+						return err
+					}
+					return x.Pow(y)
+				},
 			},
 		},
 
@@ -192,11 +258,18 @@ func init() {
 			name: "<<",
 			fn: map[TypeFlag]binaryFn{
 				CharType: func(env *Environment, a, b Object) Object {
-					return NewChar(a.(*Char).Value << b.(*Char).Value)
+					if b.(*Char).Value > -1 {
+						return NewChar(a.(*Char).Value << b.(*Char).Value)
+					}
+					return NewError("shift count type must be unsigned Int")
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					if shift := b.(*Int).Value; shift > -1 {
-						return NewInt(a.(*Int).Value << uint(shift))
+					x, y := a.(*Int), b.(*Int)
+					if y.Value > -1 {
+						if err := checkBig(env, x.bits(y, false)/8); err != nil {
+							return err
+						}
+						return x.Lsh(y)
 					}
 					return NewError("shift count type must be unsigned Int")
 				},
@@ -207,11 +280,14 @@ func init() {
 			name: ">>",
 			fn: map[TypeFlag]binaryFn{
 				CharType: func(env *Environment, a, b Object) Object {
-					return NewChar(a.(*Char).Value >> b.(*Char).Value)
+					if b.(*Char).Value > -1 {
+						return NewChar(a.(*Char).Value >> b.(*Char).Value)
+					}
+					return NewError("shift count type must be unsigned Int")
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					if shift := b.(*Int).Value; shift > -1 {
-						return NewInt(a.(*Int).Value >> uint(shift))
+					if b.(*Int).Value > -1 {
+						return a.(*Int).Rsh(b.(*Int))
 					}
 					return NewError("shift count type must be unsigned Int")
 				},
@@ -222,7 +298,11 @@ func init() {
 			name: "&",
 			fn: map[TypeFlag]binaryFn{
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value & b.(*Int).Value)
+					x, y := a.(*Int), b.(*Int)
+					if x.IsBig() || y.IsBig() {
+						return bitwise(x, y, (*big.Int).And)
+					}
+					return NewInt(x.Value & y.Value)
 				},
 			},
 		},
@@ -230,7 +310,11 @@ func init() {
 			name: "|",
 			fn: map[TypeFlag]binaryFn{
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value | b.(*Int).Value)
+					x, y := a.(*Int), b.(*Int)
+					if x.IsBig() || y.IsBig() {
+						return bitwise(x, y, (*big.Int).Or)
+					}
+					return NewInt(x.Value | y.Value)
 				},
 			},
 		},
@@ -239,7 +323,11 @@ func init() {
 			name: "^",
 			fn: map[TypeFlag]binaryFn{
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewInt(a.(*Int).Value ^ b.(*Int).Value)
+					x, y := a.(*Int), b.(*Int)
+					if x.IsBig() || y.IsBig() {
+						return bitwise(x, y, (*big.Int).Xor)
+					}
+					return NewInt(x.Value ^ y.Value)
 				},
 			},
 		},
@@ -251,11 +339,17 @@ func init() {
 					return NewBool(a.(*Char).Value < b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value < b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) < 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value < b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) < 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) < 0)
+				},
 			},
 		},
 
@@ -266,11 +360,17 @@ func init() {
 					return NewBool(a.(*Char).Value > b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value > b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) > 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value > b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) > 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) > 0)
+				},
 			},
 		},
 
@@ -281,11 +381,17 @@ func init() {
 					return NewBool(a.(*Char).Value <= b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value <= b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) <= 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value <= b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) <= 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) <= 0)
+				},
 			},
 		},
 
@@ -296,11 +402,17 @@ func init() {
 					return NewBool(a.(*Char).Value >= b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value >= b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) >= 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value >= b.(*Float).Value)
 				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) >= 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) >= 0)
+				},
 			},
 		},
 
@@ -314,7 +426,7 @@ func init() {
 					return NewBool(a.(*Char).Value == b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value == b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) == 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value == b.(*Float).Value)
@@ -322,6 +434,19 @@ func init() {
 				StringType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*String).Value == b.(*String).Value)
 				},
+				InstanceType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Instance).Equal(b.(*Instance)))
+				},
+				RangeType: func(env *Environment, a, b Object) Object {
+					r, ok := a.(*Range)
+					return NewBool(ok && r.Equal(b.(*Range)))
+				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) == 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) == 0)
+				},
 			},
 		},
 
@@ -335,7 +460,7 @@ func init() {
 					return NewBool(a.(*Char).Value != b.(*Char).Value)
 				},
 				IntType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Int).Value != b.(*Int).Value)
+					return NewBool(a.(*Int).Cmp(b.(*Int)) != 0)
 				},
 				FloatType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*Float).Value != b.(*Float).Value)
@@ -343,6 +468,19 @@ func init() {
 				StringType: func(env *Environment, a, b Object) Object {
 					return NewBool(a.(*String).Value != b.(*String).Value)
 				},
+				InstanceType: func(env *Environment, a, b Object) Object {
+					return NewBool(!a.(*Instance).Equal(b.(*Instance)))
+				},
+				RangeType: func(env *Environment, a, b Object) Object {
+					r, ok := a.(*Range)
+					return NewBool(!ok || !r.Equal(b.(*Range)))
+				},
+				DecimalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Decimal).Cmp(b.(*Decimal)) != 0)
+				},
+				RationalType: func(env *Environment, a, b Object) Object {
+					return NewBool(a.(*Rational).Cmp(b.(*Rational)) != 0)
+				},
 			},
 		},
 
@@ -350,14 +488,23 @@ func init() {
 			name: "++",
 			fn: map[TypeFlag]binaryFn{
 				StringType: func(env *Environment, a, b Object) Object {
+					size := len(a.(*String).Value) + len(b.(*String).Value)
+					if err := env.Runtime().CheckSize(size); err != nil {
+						return err
+					}
 					return a.(*String).Concat(b.(*String))
 				},
-				ArrayType: func(env *Environment, a, b Object) Object {
-					// TODO: set up recovery. this causes a panic when used wrong.
-					return a.(*Array).Concat(b.(*Array))
-				},
+				ArrayType: concatArrays,
+				RangeType: concatArrays,
 				DictType: func(env *Environment, a, b Object) Object {
-					return a.(*Dict).Concat(b.(*Dict))
+					d, ok := a.(*Dict)
+					if !ok {
+						return NewError("mis matched types: %s, %s", a.Type(), b.Type())
+					}
+					if err := env.Runtime().CheckSize(d.Len + b.(*Dict).Len); err != nil {
+						return err
+					}
+					return d.Concat(b.(*Dict))
 				},
 			},
 		},
@@ -374,23 +521,8 @@ func init() {
 				DictType: func(env *Environment, a, b Object) Object {
 					return b.(*Dict).Contains(a)
 				},
-			},
-		},
-
-		{
-			name: "and",
-			fn: map[TypeFlag]binaryFn{
-				BoolType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Bool).Value && b.(*Bool).Value)
-				},
-			},
-		},
-
-		{
-			name: "or",
-			fn: map[TypeFlag]binaryFn{
-				BoolType: func(env *Environment, a, b Object) Object {
-					return NewBool(a.(*Bool).Value || b.(*Bool).Value)
+				RangeType: func(env *Environment, a, b Object) Object {
+					return b.(*Range).Contains(a)
 				},
 			},
 		},
@@ -400,3 +532,47 @@ func init() {
 		BinaryOps[op.name] = op
 	}
 }
+
+// checkBig : check a big Int result of about size bytes is not too large,
+// and the evaluation has not been cancelled before the time taken to make it.
+func checkBig(env *Environment, size int) *Error {
+	if err := env.Runtime().CheckSize(size); err != nil {
+		return err
+	}
+	return env.Runtime().Cancelled()
+}
+
+// concatArrays : a ++ b where a and b are arrays or ranges, ranges are made
+// into arrays of their items.
+func concatArrays(env *Environment, a, b Object) Object {
+	size := 0
+	for _, obj := range []Object{a, b} {
+		switch obj := obj.(type) {
+		case *Array:
+			size += len(obj.Elements)
+		case *Range:
+			size += obj.len()
+		default:
+			return NewError("mis matched types: %s, %s", a.Type(), b.Type())
+		}
+	}
+	if err := env.Runtime().CheckSize(size); err != nil {
+		return err
+	}
+	return a.ConvertType(ArrayType).(*Array).Concat(b.ConvertType(ArrayType))
+}
+
+// bigQuotient : x / y as a float64 when either is too large for an int.
+func bigQuotient(x, y *Int) float64 {
+	if !y.IsBig() && y.Value == 0 {
+		return math.Inf(x.big().Sign())
+	}
+	q, _ := new(big.Rat).SetFrac(x.big(), y.big()).Float64()
+	return q
+}
+
+// bitwise : x and y combined by the bitwise operation fn of big.Int, used
+// when either is too large for an int.
+func bitwise(x, y *Int, fn func(z, x, y *big.Int) *big.Int) *Int {
+	return NewBigInt(fn(new(big.Int), x.big(), y.big()))
+}
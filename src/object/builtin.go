@@ -5,10 +5,12 @@ import (
 	"strings"
 )
 
-// BuiltinFunction :
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction : env is the environment of the caller, nil when called
+// from the vm.
+type BuiltinFunction func(env *Environment, args ...Object) Object
 
-// Builtin :
+// Builtin : a function implemented in Go. Its arguments are checked against
+// ArgC and ArgT before Fn is called, see CheckArgs.
 type Builtin struct {
 	Fn      BuiltinFunction
 	Name    string
@@ -16,6 +18,8 @@ type Builtin struct {
 	ArgC    int
 	ArgT    []string
 	ReturnT string
+	// Capability : needed by the runtime to call the builtin, zero for none.
+	Capability Capability
 }
 
 // Type : return objects type as a TypeFlag
@@ -27,10 +31,134 @@ func (b *Builtin) Inspect() string {
 		b.Name, strings.Join(b.ArgT, ", "), b.ReturnT)
 }
 
-// NewBuiltin : return new initialized instance of the object.
-// func NewBuiltin(fn BuiltinFunction) *Builtin {
-// 	return &Builtin{Fn: fn, argC: }
-// }
+// Argument types understood in ArgT, besides the name of any TypeFlag. A
+// type ending in "..." matches all of the remaining arguments and is only
+// allowed last. A type ending in "?" is of an argument which can be left
+// out, only other optional arguments may follow it. The ArgC of a builtin
+// with either is -1.
+var argTypes = map[string]func(Object) bool{
+	"Any": func(Object) bool { return true },
+	"Atom": func(obj Object) bool {
+		switch obj.Type() {
+		case CharType, IntType, DecimalType, RationalType, FloatType, BoolType, StringType:
+			return true
+		}
+		return false
+	},
+	"Numeric":  func(obj Object) bool { _, ok := obj.(Numeric); return ok },
+	"Iter":     func(obj Object) bool { _, ok := obj.(Iterable); return ok },
+	"Hashable": func(obj Object) bool { _, ok := AsHashable(obj); return ok },
+}
+
+func init() {
+	for i, name := range typeName {
+		which := TypeFlag(i)
+		argTypes[name] = func(obj Object) bool { return obj.Type() == which }
+	}
+}
+
+// variadic : the type of the arguments matched by a trailing "..." type.
+func (b *Builtin) variadic() (string, bool) {
+	if len(b.ArgT) == 0 {
+		return "", false
+	}
+	last := b.ArgT[len(b.ArgT)-1]
+	return strings.TrimSuffix(last, "..."), strings.HasSuffix(last, "...")
+}
+
+// Arity : the fewest and the most arguments the builtin takes, the most is
+// -1 when it is variadic.
+func (b *Builtin) Arity() (int, int) {
+	least, most := 0, len(b.ArgT)
+	for _, t := range b.ArgT {
+		switch {
+		case strings.HasSuffix(t, "..."):
+			most = -1
+		case !strings.HasSuffix(t, "?"):
+			least++
+		}
+	}
+	return least, most
+}
+
+// ArgType : the type wanted for argument i, empty when there is none.
+func (b *Builtin) ArgType(i int) string {
+	if i < len(b.ArgT) {
+		return strings.TrimSuffix(strings.TrimSuffix(b.ArgT[i], "..."), "?")
+	}
+	if rest, ok := b.variadic(); ok {
+		return rest
+	}
+	return ""
+}
+
+// Validate : check the metadata of the builtin is complete and consistent,
+// so it can be registered.
+func (b *Builtin) Validate() error {
+	if b.Name == "" || b.Fn == nil {
+		return fmt.Errorf("builtin needs a Name and Fn")
+	}
+	least, most := b.Arity()
+	if least != most && b.ArgC != -1 {
+		return fmt.Errorf("builtin %s has variadic or optional arguments, ArgC must be -1", b.Name)
+	}
+	if least == most && b.ArgC != len(b.ArgT) {
+		return fmt.Errorf("builtin %s has ArgC %d but %d ArgT", b.Name, b.ArgC, len(b.ArgT))
+	}
+	optional := false
+	for i, t := range b.ArgT {
+		if strings.HasSuffix(t, "...") && i != len(b.ArgT)-1 {
+			return fmt.Errorf("builtin %s: only the last argument can be %s", b.Name, t)
+		}
+		if optional && !strings.HasSuffix(t, "?") {
+			return fmt.Errorf("builtin %s: argument %s can not follow an optional argument", b.Name, t)
+		}
+		optional = strings.HasSuffix(t, "?")
+		if _, ok := argTypes[b.ArgType(i)]; !ok {
+			return fmt.Errorf("builtin %s: unknown argument type %s", b.Name, t)
+		}
+	}
+	return nil
+}
+
+// CheckArgCount : return an Error if n arguments are too few or too many.
+func (b *Builtin) CheckArgCount(n int) *Error {
+	least, most := b.Arity()
+	if most > least && (n < least || n > most) {
+		return NewError(ArgRangeError, b.Name, least, most, n)
+	}
+	if n < least || most == least && n != least {
+		return NewError(InvalidArgLenError, b.Name, least, n)
+	}
+	return nil
+}
+
+// CheckArgs : return an Error if args do not match ArgC and ArgT.
+func (b *Builtin) CheckArgs(args []Object) *Error {
+	if err := b.CheckArgCount(len(args)); err != nil {
+		return err
+	}
+	for i, arg := range args {
+		want := b.ArgType(i)
+		if match, ok := argTypes[want]; ok && !match(arg) {
+			return NewError(ArgTypeError, i+1, b.Name, want, arg.Type())
+		}
+	}
+	return nil
+}
+
+// Call : check the runtime allows the builtin and its arguments then call Fn. env is the environment of the
+// caller, nil when called from the vm. A panic in Fn is returned as an InternalError.
+func (b *Builtin) Call(env *Environment, args []Object) (result Object) {
+	if !env.Runtime().Allows(b.Capability) {
+		return NewPermissionError(b.Name, b.Capability)
+	}
+	if err := b.CheckArgs(args); err != nil {
+		return err
+	}
+	defer Recover(&result, "builtin", b.Name)
+	return b.Fn(env, args...)
+}
 
 // ConvertType : return the conversion into the specified type
 func (b *Builtin) ConvertType(which TypeFlag) Object {
@@ -1,21 +1,16 @@
 package object
 
-import (
-	"fmt"
-	"hash/fnv"
-)
-
-// Char :
-type Char struct{ Value byte }
+// Char : a single unicode code point, the items of a String.
+type Char struct{ Value rune }
 
 // Type : return objects type as a TypeFlag
 func (c *Char) Type() TypeFlag { return CharType }
 
 // Inspect : return a string representation of the objects value.
-func (c *Char) Inspect() string { return fmt.Sprintf("'%c'", c.Value) }
+func (c *Char) Inspect() string { return string(c.Value) }
 
 // NewChar : return new initialized instance of the object.
-func NewChar(value byte) *Char { return &Char{Value: value} }
+func NewChar(value rune) *Char { return &Char{Value: value} }
 
 // ConvertType : return the conversion into the specified type
 func (c *Char) ConvertType(which TypeFlag) Object {
@@ -24,10 +19,12 @@ func (c *Char) ConvertType(which TypeFlag) Object {
 		return c
 	case IntType:
 		return NewInt(int(c.Value))
+	case DecimalType, RationalType:
+		return NewInt(int(c.Value)).ConvertType(which)
 	case FloatType:
 		return NewFloat(float64(c.Value))
 	case StringType:
-		return NewString(c.Inspect())
+		return NewString(string(c.Value))
 	case BoolType:
 		return NewBool(c.Value != 0)
 	default:
@@ -44,9 +41,6 @@ func (c *Char) Abs() *Char { return c }
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Methods needed to satisfy the Hashable interface:
 
-// Hash : hash value of char
-func (c *Char) Hash() HashKey {
-	h := fnv.New64a()
-	h.Write([]byte{c.Value})
-	return HashKey{Type: c.Type(), Value: h.Sum64()}
-}
+// Hash : hash value of char, the same as the String of just the char as the
+// two are equal.
+func (c *Char) Hash() HashKey { return NewString(string(c.Value)).Hash() }
@@ -0,0 +1,79 @@
+package object
+
+import (
+	"dito/src/token"
+	"fmt"
+	"sort"
+)
+
+// CompiledFunction : a function body lowered to bytecode by the compiler.
+// Instructions are only understood by the vm package.
+type CompiledFunction struct {
+	Instructions []byte
+	NumLocals    int
+	NumParams    int
+	ParamTypes   []string // see ParamTypes.
+	ReturnT      string   // the annotated type of its return value, or empty.
+	Name         string
+	IsLambda     bool
+	Generator    bool
+	Positions    []Position // in order of Offset, see PosAt.
+}
+
+// Position : the source position of the instructions from Offset up to the
+// Offset of the next Position.
+type Position struct {
+	Offset int
+	Pos    token.Pos
+}
+
+// PosAt : return the source position of the instruction at offset ip, or
+// of any of its operands.
+func (cf *CompiledFunction) PosAt(ip int) token.Pos {
+	i := sort.Search(len(cf.Positions), func(i int) bool {
+		return cf.Positions[i].Offset > ip
+	})
+	if i == 0 {
+		return token.Pos{}
+	}
+	return cf.Positions[i-1].Pos
+}
+
+// Type : return objects type as a TypeFlag
+func (cf *CompiledFunction) Type() TypeFlag {
+	if cf.IsLambda {
+		return LambdaType
+	}
+	return FunctionType
+}
+
+// Inspect : return a string representation of the objects value.
+func (cf *CompiledFunction) Inspect() string {
+	if cf.IsLambda {
+		return "<Lambda>"
+	}
+	return fmt.Sprintf("<function %s>", cf.Name)
+}
+
+// ConvertType : return the conversion into the specified type
+func (cf *CompiledFunction) ConvertType(which TypeFlag) Object {
+	return NewError("Argument to %s not supported, got %s", cf.Type(), which)
+}
+
+// Closure : a compiled function paired with the free variables it captured
+// when it was created.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+// Type : return objects type as a TypeFlag
+func (c *Closure) Type() TypeFlag { return c.Fn.Type() }
+
+// Inspect : return a string representation of the objects value.
+func (c *Closure) Inspect() string { return c.Fn.Inspect() }
+
+// ConvertType : return the conversion into the specified type
+func (c *Closure) ConvertType(which TypeFlag) Object {
+	return NewError("Argument to %s not supported, got %s", c.Type(), which)
+}
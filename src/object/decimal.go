@@ -0,0 +1,320 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Rounding : how a Decimal is rounded to fewer places.
+type Rounding int
+
+// Ways of rounding, the names used by dito code are in roundingName.
+const (
+	RoundHalfEven Rounding = iota // to the nearest, ties to an even digit.
+	RoundHalfUp                   // to the nearest, ties away from zero.
+	RoundDown                     // towards zero.
+	RoundUp                       // away from zero.
+	RoundFloor                    // towards negative infinity.
+	RoundCeiling                  // towards positive infinity.
+)
+
+var roundingName = [...]string{"half_even", "half_up", "down", "up", "floor", "ceiling"}
+
+func (r Rounding) String() string { return roundingName[r] }
+
+// ParseRounding : the Rounding with the name given.
+func ParseRounding(name string) (Rounding, bool) {
+	for i, n := range roundingName {
+		if n == name {
+			return Rounding(i), true
+		}
+	}
+	return 0, false
+}
+
+// DecimalContext : the most places kept by the result of multiplying or
+// dividing Decimals, unless an operand has more, and how the result is
+// rounded to them. Adding and subtracting is always exact.
+type DecimalContext struct {
+	Places   int
+	Rounding Rounding
+}
+
+// DefaultDecimalContext : the context of a Runtime unless it is changed.
+var DefaultDecimalContext = DecimalContext{Places: 28, Rounding: RoundHalfEven}
+
+// Decimal : an exact base 10 number, Value / 10**Scale. The Scale is the
+// number of places shown, so 1.50 keeps its trailing zero, but it does not
+// change which number a Decimal is.
+type Decimal struct {
+	Value *big.Int
+	Scale int
+}
+
+// NewDecimal : return the Decimal value / 10**scale.
+func NewDecimal(value *big.Int, scale int) *Decimal {
+	if scale < 0 {
+		value = new(big.Int).Mul(value, pow10(-scale))
+		scale = 0
+	}
+	return &Decimal{Value: value, Scale: scale}
+}
+
+// ParseDecimal : the Decimal written in s, like "-12.50".
+func ParseDecimal(s string) (*Decimal, bool) {
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	digits := whole + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, false
+	}
+	value, _ := new(big.Int).SetString(sign+digits, 10)
+	return NewDecimal(value, len(frac)), true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Type : return objects type as a TypeFlag
+func (d *Decimal) Type() TypeFlag { return DecimalType }
+
+// Inspect : return a string representation of the objects value.
+func (d *Decimal) Inspect() string {
+	s := new(big.Int).Abs(d.Value).String()
+	if d.Scale > 0 {
+		if len(s) <= d.Scale {
+			s = strings.Repeat("0", d.Scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.Scale] + "." + s[len(s)-d.Scale:]
+	}
+	if d.Value.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// ConvertType : return the conversion into the specified type
+func (d *Decimal) ConvertType(which TypeFlag) Object {
+	switch which {
+	case DecimalType:
+		return d
+	case IntType:
+		return NewBigInt(new(big.Int).Quo(d.Value, pow10(d.Scale)))
+	case RationalType:
+		return NewRational(d.rat())
+	case FloatType:
+		f, _ := d.rat().Float64()
+		return NewFloat(f)
+	case StringType:
+		return NewString(d.Inspect())
+	case BoolType:
+		return NewBool(d.Value.Sign() != 0)
+	default:
+		return NewError(ConvertTypeError, d.Type(), which)
+	}
+}
+
+func (d *Decimal) rat() *big.Rat { return new(big.Rat).SetFrac(d.Value, pow10(d.Scale)) }
+
+// rescale : the Value of d at a scale no smaller than its own.
+func (d *Decimal) rescale(scale int) *big.Int {
+	if scale == d.Scale {
+		return d.Value
+	}
+	return new(big.Int).Mul(d.Value, pow10(scale-d.Scale))
+}
+
+// align : the Values of d and o at the larger of their scales.
+func (d *Decimal) align(o *Decimal) (x, y *big.Int, scale int) {
+	scale = d.Scale
+	if o.Scale > scale {
+		scale = o.Scale
+	}
+	return d.rescale(scale), o.rescale(scale), scale
+}
+
+// Round : return d with places places, rounded by mode when it has more.
+func (d *Decimal) Round(places int, mode Rounding) *Decimal {
+	if places >= d.Scale {
+		return NewDecimal(d.rescale(places), places)
+	}
+	return NewDecimal(divRound(d.Value, pow10(d.Scale-places), mode), places)
+}
+
+// fit : d rounded to the places of ctx, but no fewer than keep, without the
+// trailing zeros beyond keep places.
+func (d *Decimal) fit(ctx DecimalContext, keep int) *Decimal {
+	places := ctx.Places
+	if keep > places {
+		places = keep
+	}
+	if d.Scale > places {
+		d = d.Round(places, ctx.Rounding)
+	}
+	value, scale := d.Value, d.Scale
+	ten, r := big.NewInt(10), new(big.Int)
+	for scale > keep {
+		q, m := new(big.Int).QuoRem(value, ten, r)
+		if m.Sign() != 0 {
+			break
+		}
+		value, scale = q, scale-1
+	}
+	return NewDecimal(value, scale)
+}
+
+// divRound : n / d rounded to an integer by mode.
+func divRound(n, d *big.Int, mode Rounding) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	negative := n.Sign() != d.Sign()
+	// how twice the remainder compares to d, so zero for a tie.
+	twice := new(big.Int).Abs(r)
+	half := twice.Lsh(twice, 1).Cmp(new(big.Int).Abs(d))
+	var away bool
+	switch mode {
+	case RoundHalfEven:
+		away = half > 0 || half == 0 && q.Bit(0) == 1
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = negative
+	case RoundCeiling:
+		away = !negative
+	}
+	if !away {
+		return q
+	}
+	if negative {
+		return q.Sub(q, big.NewInt(1))
+	}
+	return q.Add(q, big.NewInt(1))
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Arithmetic, used by the binary operators:
+
+// Add : return d + o.
+func (d *Decimal) Add(o *Decimal) *Decimal {
+	x, y, scale := d.align(o)
+	return NewDecimal(new(big.Int).Add(x, y), scale)
+}
+
+// Sub : return d - o.
+func (d *Decimal) Sub(o *Decimal) *Decimal {
+	x, y, scale := d.align(o)
+	return NewDecimal(new(big.Int).Sub(x, y), scale)
+}
+
+// Mul : return d * o rounded to fit ctx.
+func (d *Decimal) Mul(o *Decimal, ctx DecimalContext) *Decimal {
+	product := NewDecimal(new(big.Int).Mul(d.Value, o.Value), d.Scale+o.Scale)
+	return product.fit(ctx, maxInt(d.Scale, o.Scale))
+}
+
+// Quo : return d / o rounded to fit ctx.
+func (d *Decimal) Quo(o *Decimal, ctx DecimalContext) Object {
+	if o.Value.Sign() == 0 {
+		return NewError(DivisionByZeroError)
+	}
+	keep := maxInt(d.Scale, o.Scale)
+	places := maxInt(ctx.Places, keep)
+	n := new(big.Int).Mul(d.Value, pow10(places-d.Scale+o.Scale))
+	return NewDecimal(divRound(n, o.Value, ctx.Rounding), places).fit(ctx, keep)
+}
+
+// IntQuo : return d // o, the quotient truncated towards zero.
+func (d *Decimal) IntQuo(o *Decimal) Object {
+	if o.Value.Sign() == 0 {
+		return NewError(DivisionByZeroError)
+	}
+	x, y, _ := d.align(o)
+	return NewDecimal(new(big.Int).Quo(x, y), 0)
+}
+
+// Rem : return d % o, with the sign of d.
+func (d *Decimal) Rem(o *Decimal) Object {
+	if o.Value.Sign() == 0 {
+		return NewError(DivisionByZeroError)
+	}
+	x, y, scale := d.align(o)
+	return NewDecimal(new(big.Int).Rem(x, y), scale)
+}
+
+// Pow : return d ** o rounded to fit ctx, o must be a whole number.
+func (d *Decimal) Pow(o *Decimal, ctx DecimalContext) Object {
+	n, ok := o.exponent()
+	if !ok {
+		return NewError("exponent %s of a Decimal must be a whole number", o.Inspect())
+	}
+	if n < 0 {
+		one := NewDecimal(big.NewInt(1), 0)
+		return one.Quo(d.Pow(NewDecimal(big.NewInt(int64(-n)), 0), ctx).(*Decimal), ctx)
+	}
+	power := NewDecimal(new(big.Int).Exp(d.Value, big.NewInt(int64(n)), nil), d.Scale*n)
+	return power.fit(ctx, d.Scale)
+}
+
+// exponent : the value of d as an int when it is a whole number.
+func (d *Decimal) exponent() (int, bool) {
+	q, r := new(big.Int).QuoRem(d.Value, pow10(d.Scale), new(big.Int))
+	if r.Sign() != 0 || !q.IsInt64() || q.Int64() <= math.MinInt {
+		return 0, false
+	}
+	return int(q.Int64()), true
+}
+
+// bits : an estimate of the number of bits needed to hold d ** o.
+func (d *Decimal) bits(o *Decimal) int {
+	n, ok := o.exponent()
+	if !ok {
+		return 0
+	}
+	return powBits(d.Value.BitLen()+4*d.Scale, int64(n))
+}
+
+// Cmp : return -1, 0 or +1 as d is less than, equal to or greater than o.
+func (d *Decimal) Cmp(o *Decimal) int {
+	x, y, _ := d.align(o)
+	return x.Cmp(y)
+}
+
+// Neg : return -d.
+func (d *Decimal) Neg() *Decimal { return NewDecimal(new(big.Int).Neg(d.Value), d.Scale) }
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Methods needed to satisfy the Numeric interface:
+
+// Abs : return the absolute value of an number
+func (d *Decimal) Abs() Object {
+	if d.Value.Sign() >= 0 {
+		return d
+	}
+	return d.Neg()
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Methods needed to satisfy the Hashable interface:
+
+// Hash : hash value of the number, the same whatever its scale, see
+// numberHash.
+func (d *Decimal) Hash() HashKey { return numberHash(d.rat()) }
@@ -8,8 +8,9 @@ import (
 
 // Dict :
 type Dict struct {
-	Items map[HashKey]DictItem
-	Len   int
+	Items  map[HashKey]DictItem
+	Len    int
+	Frozen bool
 }
 
 // DictItem :
@@ -22,16 +23,21 @@ type DictItem struct {
 func (d *Dict) Type() TypeFlag { return DictType }
 
 // Inspect : return a string representation of the objects value.
-func (d *Dict) Inspect() string {
+func (d *Dict) Inspect() string { return inspectIn(d, map[Object]bool{}) }
+
+func (d *Dict) inspect(seen map[Object]bool) string {
 	var out bytes.Buffer
 	items := []string{}
 	for _, item := range d.Items {
 		if item.Key.Type() == StringType {
 			items = append(items, fmt.Sprintf("\"%s\": %s",
-				item.Key.Inspect(), item.Value.Inspect()))
+				item.Key.Inspect(), inspectIn(item.Value, seen)))
+		} else if item.Key.Type() == CharType {
+			items = append(items, fmt.Sprintf("'%s': %s",
+				item.Key.Inspect(), inspectIn(item.Value, seen)))
 		} else {
 			items = append(items, fmt.Sprintf("%s: %s",
-				item.Key.Inspect(), item.Value.Inspect()))
+				inspectIn(item.Key, seen), inspectIn(item.Value, seen)))
 		}
 	}
 	out.WriteString("{")
@@ -63,7 +69,7 @@ func (d *Dict) Length() Object { return &Int{Value: d.Len} }
 
 // GetItem : return the item at the position provided by the hashkey
 func (d *Dict) GetItem(key Object) Object {
-	hashKey, ok := key.(Hashable)
+	hashKey, ok := AsHashable(key)
 	if !ok {
 		return NewError("type '%s' is not hashable", key.Type())
 	}
@@ -75,12 +81,16 @@ func (d *Dict) GetItem(key Object) Object {
 
 // SetItem : set item at index except char or a string.
 func (d *Dict) SetItem(key Object, val Object) Object {
-	hashKey, ok := key.(Hashable)
+	if d.Frozen {
+		return frozenError(d.Type().String())
+	}
+	hashKey, ok := AsHashable(key)
 	if !ok {
 		return NewError("type '%s' is not hashable", key.Type())
 	}
 	if item, ok := d.Items[hashKey.Hash()]; ok {
 		item.Value = val
+		d.Items[hashKey.Hash()] = item
 	} else {
 		d.Items[hashKey.Hash()] = DictItem{Key: key, Value: val}
 		d.Len++
@@ -99,6 +109,9 @@ func (d *Dict) Concat(other Object) Object {
 	if !ok {
 		return NewError("TypeError: cannot conncat type %s to Dict", other.Type())
 	}
+	if d.Frozen {
+		return frozenError(d.Type().String())
+	}
 	for _, item := range otherDict.Items {
 		d.SetItem(item.Key, item.Value)
 	}
@@ -107,7 +120,7 @@ func (d *Dict) Concat(other Object) Object {
 
 // Contains :
 func (d *Dict) Contains(key Object) Object {
-	hashKey, ok := key.(Hashable)
+	hashKey, ok := AsHashable(key)
 	if !ok {
 		return NewError("type '%s' is not hashable", key.Type())
 	}
@@ -117,14 +130,11 @@ func (d *Dict) Contains(key Object) Object {
 	return FALSE
 }
 
-// Iter : loop through items elements in order.
-func (d *Dict) Iter() <-chan Object {
-	ch := make(chan Object)
-	go func() {
-		for _, item := range d.Items {
-			ch <- item.Key
-		}
-		close(ch)
-	}()
-	return ch
+// Iter : return an Iterator over the keys, in no particular order.
+func (d *Dict) Iter() Iterator {
+	keys := make([]Object, 0, len(d.Items))
+	for _, item := range d.Items {
+		keys = append(keys, item.Key)
+	}
+	return NewSliceIterator(keys)
 }
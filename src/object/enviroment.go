@@ -4,6 +4,7 @@ package object
 type Variable struct {
 	value   Object
 	mutable bool
+	typ     string // the annotated type of its values, empty for any.
 }
 
 // IsMutable : can it be changed
@@ -16,37 +17,101 @@ func (v *Variable) Unpack() Object {
 	return v.value
 }
 
+// TypeName : the type the variable is annotated with, empty when it has none.
+func (v *Variable) TypeName() string {
+	return v.typ
+}
+
 // Environment : Holds the environment variables created by the user. Pretty much
 // a symbol table.
+//
+// Each function call and module has its own environment, enclosed by the one
+// the function was defined in. The blocks of if, for and try statements are
+// scopes of their own within it. Assignments can change the variables of the
+// blocks around them, but those of an enclosing function or the module only
+// once they are declared nonlocal.
 type Environment struct {
-	store map[string]Variable
-	outer *Environment
+	store     map[string]Variable
+	outer     *Environment
+	runtime   *Runtime
+	yield     func(Object) bool
+	block     bool            // a block within a function or module.
+	nonlocals map[string]bool // names declared nonlocal in the function.
+}
+
+// SystemVars : immutable variables defined in every initial environment.
+var SystemVars = map[string]Object{
+	"STDIN":  STDIN,
+	"STDOUT": STDOUT,
+	"STDERR": STDERR,
 }
 
 // InitialEnvironment : Define the initial environment scope. with system variables etc.
 func InitialEnvironment() *Environment {
-	return &Environment{
-		store: map[string]Variable{
-			"STDIN":  Variable{value: STDIN, mutable: false},
-			"STDOUT": Variable{value: STDOUT, mutable: false},
-			"STDERR": Variable{value: STDERR, mutable: false},
-		},
+	return RuntimeEnvironment(DefaultRuntime)
+}
+
+// RuntimeEnvironment : Define the initial environment scope of an interpreter
+// with its own runtime, the system variables are its standard streams and
+// only defined when it allows CapStdio.
+func RuntimeEnvironment(rt *Runtime) *Environment {
+	env := NewEnvironment()
+	env.runtime = rt
+	if rt.Allows(CapStdio) {
+		env.Set("STDIN", rt.Stdin, false)
+		env.Set("STDOUT", rt.Stdout, false)
+		env.Set("STDERR", rt.Stderr, false)
 	}
+	return env
 }
 
 // NewEnvironment : Define a new environment scope.
 func NewEnvironment() *Environment {
-	s := make(map[string]Variable)
-	return &Environment{store: s, outer: nil}
+	return &Environment{}
 }
 
 // NewEnclosedEnvironment : Define a new environment scope within another.
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.runtime = outer.runtime
 	return env
 }
 
+// NewBlockEnvironment : Define the scope of a block within another. Unlike
+// the scope of a function, assignments in it can change the variables of the
+// scopes around it.
+func NewBlockEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.block = true
+	return env
+}
+
+// Runtime : the runtime the environment belongs to.
+func (e *Environment) Runtime() *Runtime {
+	if e == nil || e.runtime == nil {
+		return DefaultRuntime
+	}
+	return e.runtime
+}
+
+// SetYield : make env the scope of a generator body, its yield statements
+// pass their values to fn.
+func (e *Environment) SetYield(fn func(Object) bool) {
+	e.yield = fn
+}
+
+// Yield : pass val to the consumer of the generator the scope belongs to.
+// Returns false when the generator has been stopped and should return.
+func (e *Environment) Yield(val Object) bool {
+	for ; e != nil; e = e.outer {
+		if e.yield != nil {
+			return e.yield(val)
+		}
+	}
+	return false
+}
+
 // Get : get a variable inside the current scope
 func (e *Environment) Get(name string) (Object, bool) {
 	v, ok := e.store[name]
@@ -67,10 +132,85 @@ func (e *Environment) GetVar(name string) (Variable, bool) {
 
 // Set : set a variable inside the current scope.
 func (e *Environment) Set(name string, val Object, mut bool) Object {
-	e.store[name] = Variable{value: val, mutable: mut}
+	if e.store == nil {
+		// most blocks define nothing, so their store is made when needed.
+		e.store = make(map[string]Variable)
+	}
+	// an assignment keeps the annotated type of the variable.
+	e.store[name] = Variable{value: val, mutable: mut, typ: e.store[name].typ}
 	return val
 }
 
+// Annotate : give the variable name of the current scope a type, which the
+// values assigned to it must have.
+func (e *Environment) Annotate(name, typ string) {
+	if v, ok := e.store[name]; ok {
+		v.typ = typ
+		e.store[name] = v
+	}
+}
+
+// Declare : set a new variable inside the current scope. Returns an Error
+// when the scope already has a variable of that name.
+func (e *Environment) Declare(name string, val Object, mut bool) Object {
+	if _, ok := e.store[name]; ok {
+		return NewError("identifier '%s' is already declared in this scope", name)
+	}
+	return e.Set(name, val, mut)
+}
+
+// function : the scope of the function or module e is in.
+func (e *Environment) function() *Environment {
+	for e.block {
+		e = e.outer
+	}
+	return e
+}
+
+// Nonlocal : declare name nonlocal in the function e is in, so assignments
+// to it change the variable of an enclosing scope. Returns an Error when none
+// of them have the variable.
+func (e *Environment) Nonlocal(name string) *Error {
+	fn := e.function()
+	if fn.outer == nil {
+		return NewError("'nonlocal' outside function")
+	}
+	if _, ok := fn.outer.GetVar(name); !ok {
+		return NewError("no binding for nonlocal '%s' found", name)
+	}
+	if fn.nonlocals == nil {
+		fn.nonlocals = make(map[string]bool)
+	}
+	fn.nonlocals[name] = true
+	return nil
+}
+
+// Target : the scope an assignment to name changes, which is the innermost
+// scope with the variable in the current function. Variables of enclosing
+// scopes can only be the target when declared nonlocal.
+func (e *Environment) Target(name string) (*Environment, *Error) {
+	scope := e
+	for {
+		if _, ok := scope.store[name]; ok {
+			return scope, nil
+		}
+		if !scope.block {
+			break
+		}
+		scope = scope.outer
+	}
+	nonlocal := scope.nonlocals[name]
+	for scope = scope.outer; scope != nil; scope = scope.outer {
+		if _, ok := scope.store[name]; ok {
+			if !nonlocal {
+				return nil, NewError("cannot assign to '%s' of an enclosing scope, declare it nonlocal", name)
+			}
+			return scope, nil
+		}
+	}
+	return nil, NewError("identifier not found: '%s'", name)
+}
+
 // need to think about the enforcement of constants.
 func (e *Environment) existsAndMutable(name string) (bool, bool) {
 	v, ok := e.store[name]
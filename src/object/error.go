@@ -1,11 +1,27 @@
 package object
 
 import (
+	"bytes"
+	"dito/src/token"
 	"fmt"
+	"strings"
 )
 
-// Error : builtin Error type.
-type Error struct{ Message string }
+// Error : builtin Error type. Trace records the calls the error was returned
+// through as the stack unwound, innermost first. Kind distinguishes errors
+// raised by the interpreter itself, such as LimitError, from the rest.
+type Error struct {
+	Message string
+	Kind    string
+	Trace   []TraceFrame
+}
+
+// TraceFrame : a call site an error passed through. Function is the name of
+// the function the call was made from.
+type TraceFrame struct {
+	Function string
+	Pos      token.Pos
+}
 
 // Type : return objects type as a TypeFlag
 func (e *Error) Type() TypeFlag { return ErrorType }
@@ -23,8 +39,131 @@ func (e *Error) ConvertType(which TypeFlag) Object {
 	return NewError(ConvertTypeError, e.Type(), which)
 }
 
+// AddFrame : record the call site the error is being returned from. The
+// function it belongs to is filled in by NameFrames.
+func (e *Error) AddFrame(pos token.Pos) {
+	e.Trace = append(e.Trace, TraceFrame{Pos: pos})
+}
+
+// Traced : report whether the error has a frame in the function it is being
+// returned through. Frames are named as the error leaves their function, so
+// an error without an unnamed frame has not been given one by it yet.
+func (e *Error) Traced() bool {
+	return len(e.Trace) > 0 && e.Trace[len(e.Trace)-1].Function == ""
+}
+
+// NameFrames : name the function of every frame added since the error
+// last left a function.
+func (e *Error) NameFrames(function string) {
+	for i := len(e.Trace) - 1; i >= 0 && e.Trace[i].Function == ""; i-- {
+		e.Trace[i].Function = function
+	}
+}
+
+// Traceback : return a python style traceback of the error, most recent
+// call last.
+func (e *Error) Traceback() string {
+	var out bytes.Buffer
+	if len(e.Trace) > 0 {
+		out.WriteString("Traceback (most recent call last):\n")
+	}
+	for i := len(e.Trace) - 1; i >= 0; i-- {
+		frame := e.Trace[i]
+		function := frame.Function
+		if function == "" {
+			function = "<module>"
+		}
+		fmt.Fprintf(&out, "  File %q, line %d, in %s\n",
+			frame.Pos.Filename(), frame.Pos.Line, function)
+		line := frame.Pos.Source.Line(frame.Pos.Line)
+		trimmed := strings.TrimLeft(line, " \t")
+		column := frame.Pos.Column - 1 - (len(line) - len(trimmed))
+		if trimmed != "" && column >= 0 {
+			out.WriteString(token.FormatTrace(strings.TrimRight(trimmed, " \t\r"), column, "^"))
+		}
+	}
+	fmt.Fprintf(&out, "%s: %s\n", e.KindName(), e.Message)
+	return out.String()
+}
+
+// KindName : the Kind of the error, or Error when it has none.
+func (e *Error) KindName() string {
+	if e.Kind == "" {
+		return ErrorType.String()
+	}
+	return e.Kind
+}
+
+// Exception : an Error caught by a try statement. Unlike an Error it is an
+// ordinary value, so it can be stored and passed around without propagating.
+type Exception struct {
+	Err *Error
+}
+
+// Type : return objects type as a TypeFlag
+func (e *Exception) Type() TypeFlag { return ExceptionType }
+
+// Inspect : return a string representation of the objects value.
+func (e *Exception) Inspect() string { return ExceptionType.String() + ", " + e.Err.Message }
+
+// ConvertType : return the conversion into the specified type
+func (e *Exception) ConvertType(which TypeFlag) Object {
+	switch which {
+	case ExceptionType:
+		return e
+	case StringType:
+		return NewString(e.Err.Message)
+	case BoolType:
+		return TRUE
+	default:
+		return NewError(ConvertTypeError, e.Type(), which)
+	}
+}
+
+// Raise : return the Error raised by 'raise val'. Raising a caught Exception
+// raises its message again with a fresh trace.
+func Raise(val Object) *Error {
+	switch val := val.(type) {
+	case *String:
+		return &Error{Message: val.Value}
+	case *Exception:
+		return &Error{Message: val.Err.Message, Kind: val.Err.Kind}
+	default:
+		return NewError("Argument to `raise` not supported, got %s", val.Type())
+	}
+}
+
 // some pre defined errors for consistency.
 const (
-	InvalidArgLenError = "Wrong number of args to function %s. Want=%d. Got=%d."
-	ConvertTypeError   = "Cannot convet type '%s' to type '%s'"
+	InvalidArgLenError  = "Wrong number of args to function %s. Want=%d. Got=%d."
+	ArgRangeError       = "Wrong number of args to function %s. Want=%d to %d. Got=%d."
+	ArgTypeError        = "Argument %d to function %s not supported. Want=%s. Got=%s."
+	ReturnTypeError     = "Return value of function %s not supported. Want=%s. Got=%s."
+	VarTypeError        = "Value of '%s' not supported. Want=%s. Got=%s."
+	ConvertTypeError    = "Cannot convet type '%s' to type '%s'"
+	ModuleAttrError     = "module '%s' has no attribute '%s'"
+	ConcatTypeError     = "Cannot concatenate '%s' with '%s'"
+	DivisionByZeroError = "division by zero"
 )
+
+// LimitError : the Kind of the errors raised when code exceeds a limit of
+// its Runtime or its evaluation is cancelled.
+const LimitError = "LimitError"
+
+// RecursionError : the Kind of the errors raised when calls are nested deeper
+// than the interpreter allows, before the Go stack they run on overflows.
+const RecursionError = "RecursionError"
+
+// NewRecursionError : return the error for calls nested too deep.
+func NewRecursionError() *Error {
+	err := NewError("maximum call depth exceeded")
+	err.Kind = RecursionError
+	return err
+}
+
+// Uncatchable : report whether the error stops the evaluation even inside a
+// try statement, so code cannot escape the limits or capabilities of its
+// Runtime by catching them.
+func (e *Error) Uncatchable() bool {
+	return e.Kind == LimitError || e.Kind == PermissionError
+}
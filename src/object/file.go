@@ -1,23 +1,31 @@
 package object
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"syscall"
 )
 
-// File : wrapper around goes file system
+// File : a stream that can be read from and/or written to, either a file on
+// the file system or one of the standard streams of an interpreter.
 type File struct {
-	file *os.File
+	name string
+	r    io.Reader
+	w    io.Writer
+}
+
+// NewFile : return new initialized instance of the object. A nil reader or
+// writer makes the file write or read only.
+func NewFile(name string, r io.Reader, w io.Writer) *File {
+	return &File{name: name, r: r, w: w}
 }
 
 // Type : return objects type as a TypeFlag
 func (f *File) Type() TypeFlag { return FileType }
 
 // Inspect : return a string representation of the objects value.
-func (f *File) Inspect() string { return fmt.Sprintf("<File: %s>", f.file.Name()) }
+func (f *File) Inspect() string { return fmt.Sprintf("<File: %s>", f.name) }
 
 // ConvertType : return the conversion into the specified type
 func (f *File) ConvertType(which TypeFlag) Object {
@@ -26,7 +34,10 @@ func (f *File) ConvertType(which TypeFlag) Object {
 
 // Write :
 func (f *File) Write(str string) Object {
-	_, err := f.file.WriteString(str)
+	if f.w == nil {
+		return NewError("File %s is not writable", f.Inspect())
+	}
+	_, err := io.WriteString(f.w, str)
 	if err != nil {
 		return NewError("Could not write file %s", f.Inspect())
 	}
@@ -35,8 +46,10 @@ func (f *File) Write(str string) Object {
 
 // Read :
 func (f *File) Read() Object {
-	reader := bufio.NewReader(f.file)
-	fileBytes, err := ioutil.ReadAll(reader)
+	if f.r == nil {
+		return NewError("File %s is not readable", f.Inspect())
+	}
+	fileBytes, err := ioutil.ReadAll(f.r)
 	if err != nil {
 		return NewError("Could not read file %s", f.Inspect())
 	}
@@ -45,7 +58,14 @@ func (f *File) Read() Object {
 
 // Close :
 func (f *File) Close() Object {
-	if err := f.file.Close(); err != nil {
+	closer, ok := f.r.(io.Closer)
+	if !ok {
+		closer, ok = f.w.(io.Closer)
+	}
+	if !ok {
+		return NONE
+	}
+	if err := closer.Close(); err != nil {
 		return NewError("Could not close file %s", f.Inspect())
 	}
 	return NONE
@@ -60,7 +80,7 @@ func (fh *FileHandler) Open(path string) Object {
 	if err != nil {
 		return NewError("Could not open file '%s' (%s)", path, err)
 	}
-	return &File{fp}
+	return NewFile(fp.Name(), fp, nil)
 }
 
 // Create : return a new writable file
@@ -69,13 +89,13 @@ func (fh *FileHandler) Create(path string) Object {
 	if err != nil {
 		return NewError("Could not open file '%s' (%s)", path, err)
 	}
-	return &File{fp}
+	return NewFile(fp.Name(), nil, fp)
 }
 
 // OS VARS
 var (
 	FILE   = &FileHandler{}
-	STDIN  = &File{os.NewFile(uintptr(syscall.Stdin), "/dev/stdin")}
-	STDOUT = &File{os.NewFile(uintptr(syscall.Stdout), "/dev/stdout")}
-	STDERR = &File{os.NewFile(uintptr(syscall.Stderr), "/dev/stderr")}
+	STDIN  = NewFile("/dev/stdin", os.Stdin, nil)
+	STDOUT = NewFile("/dev/stdout", nil, os.Stdout)
+	STDERR = NewFile("/dev/stderr", nil, os.Stderr)
 )
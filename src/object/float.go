@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"math/big"
 	"strconv"
 )
 
@@ -27,7 +28,25 @@ func (f *Float) ConvertType(which TypeFlag) Object {
 	case FloatType:
 		return f
 	case IntType:
-		return NewInt(int(f.Value))
+		if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+			return NewError("cannot convert %s to %s", f.Inspect(), which)
+		}
+		if f.Value >= math.MinInt && f.Value < math.MaxInt {
+			return NewInt(int(f.Value))
+		}
+		i, _ := big.NewFloat(f.Value).Int(nil)
+		return NewBigInt(i)
+	case DecimalType:
+		if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+			return NewError("cannot convert %s to %s", f.Inspect(), which)
+		}
+		d, _ := ParseDecimal(strconv.FormatFloat(f.Value, 'f', -1, 64))
+		return d
+	case RationalType:
+		if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+			return NewError("cannot convert %s to %s", f.Inspect(), which)
+		}
+		return NewRational(new(big.Rat).SetFloat64(f.Value))
 	case StringType:
 		return NewString(f.Inspect())
 	case BoolType:
@@ -48,8 +67,15 @@ func (f *Float) Abs() Object {
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Methods needed to satisfy the Hashable interface:
 
-// Hash : hash value of float
+// Hash : hash value of float, a finite one is hashed by its exact value, see
+// numberHash.
 func (f *Float) Hash() HashKey {
+	if f.Value >= math.MinInt && f.Value < math.MaxInt && f.Value == math.Trunc(f.Value) {
+		return HashKey{Type: IntType, Value: uint64(int(f.Value))}
+	}
+	if !math.IsInf(f.Value, 0) && !math.IsNaN(f.Value) {
+		return numberHash(new(big.Rat).SetFloat64(f.Value))
+	}
 	h := fnv.New64a()
 	h.Write(float64ToByte(f.Value))
 	return HashKey{Type: f.Type(), Value: h.Sum64()}
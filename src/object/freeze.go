@@ -0,0 +1,39 @@
+package object
+
+// Freeze : make obj, and every value reachable from it, immutable. Items,
+// characters and fields of frozen values can no longer be assigned to. A
+// value already frozen is skipped, which also ends the walk at cycles.
+func Freeze(obj Object) Object {
+	switch obj := obj.(type) {
+	case *Array:
+		if !obj.Frozen {
+			obj.Frozen = true
+			for _, el := range obj.Elements {
+				Freeze(el)
+			}
+		}
+	case *Dict:
+		if !obj.Frozen {
+			obj.Frozen = true
+			for _, item := range obj.Items {
+				Freeze(item.Key)
+				Freeze(item.Value)
+			}
+		}
+	case *Instance:
+		if !obj.Frozen {
+			obj.Frozen = true
+			for _, field := range obj.Fields {
+				Freeze(field)
+			}
+		}
+	case *String:
+		obj.Frozen = true
+	}
+	return obj
+}
+
+// frozenError : the error for an attempt to change a frozen value.
+func frozenError(name string) *Error {
+	return NewError("cannot modify frozen %s", name)
+}
@@ -8,9 +8,12 @@ import (
 // Function :
 type Function struct {
 	Parameters []*ast.Identifier
+	ParamTypes []string // see ParamTypes.
+	ReturnT    string   // the annotated type of its return value, or empty.
 	Name       string
 	Stmts      *ast.BlockStatement
 	Env        *Environment
+	Generator  bool // calling it returns an Iterator over what it yields.
 }
 
 // Type : return objects type as a TypeFlag
@@ -23,11 +26,13 @@ func (f *Function) Inspect() string { return fmt.Sprintf("<function %s>", f.Name
 func NewFunction(fn *ast.Function, env *Environment) *Function {
 	obj := &Function{
 		Parameters: fn.Parameters,
+		ParamTypes: ParamTypes(fn.Parameters),
+		ReturnT:    fn.ReturnTypeName(),
 		Name:       fn.Name.Value,
 		Stmts:      fn.Body,
 		Env:        env,
+		Generator:  fn.Generator,
 	}
-	env.Set(obj.Name, obj, false)
 	return obj
 }
 
@@ -0,0 +1,63 @@
+package object
+
+// InternalError : the Kind of the errors made from a Go runtime panic inside
+// the interpreter, such as a failed type assertion. They are a bug in the
+// interpreter rather than the program, but are raised like any other error
+// so the program can still catch them and the host process does not crash.
+const InternalError = "InternalError"
+
+// Recover : when deferred by a function with a named result, turns a panic
+// into an InternalError in *result naming the kind and name of what was
+// being done, like "builtin len".
+func Recover(result *Object, kind, name string) {
+	if r := recover(); r != nil {
+		err := NewError("internal error in %s %s: %v", kind, name, r)
+		err.Kind = InternalError
+		*result = err
+	}
+}
+
+// GetItem : the item of obj at key.
+func GetItem(obj, key Object) (result Object) {
+	iter, ok := obj.(Iterable)
+	if !ok {
+		return NewError("Item is not Iterable")
+	}
+	defer Recover(&result, "index of", obj.Type().String())
+	return iter.GetItem(key)
+}
+
+// SetItem : set the item of obj at key to val, checking a Dict given a new
+// key does not grow too large for rt.
+func SetItem(rt *Runtime, obj, key, val Object) (result Object) {
+	iter, ok := obj.(Iterable)
+	if !ok {
+		return NewError("Index assignment error: wrong type")
+	}
+	defer Recover(&result, "index assignment of", obj.Type().String())
+	if d, ok := obj.(*Dict); ok && d.Contains(key) == FALSE {
+		if err := rt.CheckSize(d.Len + 1); err != nil {
+			return err
+		}
+	}
+	return iter.SetItem(key, val)
+}
+
+// Slice : the items of obj from start up to end.
+func Slice(obj, start, end Object) (result Object) {
+	iter, ok := obj.(Iterable)
+	if !ok {
+		return NewError("Item is not Iterable")
+	}
+	defer Recover(&result, "slice of", obj.Type().String())
+	return iter.Slice(start, end)
+}
+
+// NextItem : the next item of it, or nil once it is done.
+func NextItem(it Iterator) (result Object) {
+	defer Recover(&result, "iteration of", it.Type().String())
+	if it.Done() {
+		return nil
+	}
+	return it.Next()
+}
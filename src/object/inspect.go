@@ -0,0 +1,24 @@
+package object
+
+// inspector : objects holding other objects, which may hold themselves.
+// inspect returns the representation of the object within the containers
+// seen around it.
+type inspector interface {
+	inspect(seen map[Object]bool) string
+}
+
+// inspectIn : return the representation of obj within the containers seen.
+// A container which is already being inspected is shown as "...", so a value
+// holding itself does not recurse forever.
+func inspectIn(obj Object, seen map[Object]bool) string {
+	in, ok := obj.(inspector)
+	if !ok {
+		return obj.Inspect()
+	}
+	if seen[obj] {
+		return "..."
+	}
+	seen[obj] = true
+	defer delete(seen, obj)
+	return in.inspect(seen)
+}
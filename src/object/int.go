@@ -2,35 +2,99 @@ package object
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"unicode/utf8"
 )
 
-// Int : builtin integer type.
-// -9223372036854775807 and 9223372036854775807
-type Int struct{ Value int }
+// Int : builtin integer type, of any size. Values which fit in a Go int are
+// held in Value with Big nil, larger ones in Big. Value is then the nearest
+// int to Big, so code which only reads Value, like an index, sees a number
+// out of its range rather than a wrong one.
+type Int struct {
+	Value int
+	Big   *big.Int
+}
 
 // Type : return objects type as a TypeFlag
 func (i *Int) Type() TypeFlag { return IntType }
 
 // Inspect : return a string representation of the objects value.
-func (i *Int) Inspect() string { return fmt.Sprintf("%d", i.Value) }
+func (i *Int) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+	return fmt.Sprintf("%d", i.Value)
+}
 
 // NewInt : return new initialized instance of the object.
 func NewInt(value int) *Int { return &Int{Value: value} }
 
+// NewBigInt : return the Int of value, which only keeps value when it does
+// not fit in an int.
+func NewBigInt(value *big.Int) *Int {
+	if value.IsInt64() && value.Int64() >= math.MinInt && value.Int64() <= math.MaxInt {
+		return NewInt(int(value.Int64()))
+	}
+	if value.Sign() < 0 {
+		return &Int{Value: math.MinInt, Big: value}
+	}
+	return &Int{Value: math.MaxInt, Big: value}
+}
+
+// ParseInt : return the Int written in s in the given base, 0 lets a prefix
+// like 0x choose it.
+func ParseInt(s string, base int) (*Int, bool) {
+	value, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, false
+	}
+	return NewBigInt(value), true
+}
+
+// big : the value as a big.Int, which must not be changed.
+func (i *Int) big() *big.Int {
+	if i.Big != nil {
+		return i.Big
+	}
+	return big.NewInt(int64(i.Value))
+}
+
+// IsBig : report whether the value does not fit in an int.
+func (i *Int) IsBig() bool { return i.Big != nil }
+
 // ConvertType : return the conversion into the specified type
 func (i *Int) ConvertType(which TypeFlag) Object {
 	switch which {
 	case IntType:
 		return i
 	case CharType:
-		return NewChar(byte(i.Value))
+		if i.Big != nil || i.Value < 0 || i.Value > utf8.MaxRune || !utf8.ValidRune(rune(i.Value)) {
+			return NewError("%s is not a valid code point", i.Inspect())
+		}
+		return NewChar(rune(i.Value))
 	case FloatType:
+		if i.Big != nil {
+			f, _ := new(big.Float).SetInt(i.Big).Float64()
+			return NewFloat(f)
+		}
 		return NewFloat(float64(i.Value))
+	case DecimalType:
+		return NewDecimal(new(big.Int).Set(i.big()), 0)
+	case RationalType:
+		return NewRational(new(big.Rat).SetInt(i.big()))
 	case StringType:
 		return NewString(i.Inspect())
 	case BoolType:
 		return NewBool(i.Value != 0)
 	case ArrayType:
+		if i.Value < 0 {
+			return NewError("negative Array length %d", i.Value)
+		}
+		if i.Big != nil {
+			return NewError("Array length %s too large", i.Inspect())
+		}
 		elements := make([]Object, i.Value)
 		for j := 0; j < i.Value; j++ {
 			elements[j] = ZERO
@@ -41,6 +105,153 @@ func (i *Int) ConvertType(which TypeFlag) Object {
 	}
 }
 
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Arithmetic, which only uses big.Int when the result does not fit in an int:
+
+// Add : return i + o.
+func (i *Int) Add(o *Int) *Int {
+	if i.Big == nil && o.Big == nil {
+		if sum := i.Value + o.Value; (sum > i.Value) == (o.Value > 0) {
+			return NewInt(sum)
+		}
+	}
+	return NewBigInt(new(big.Int).Add(i.big(), o.big()))
+}
+
+// Sub : return i - o.
+func (i *Int) Sub(o *Int) *Int {
+	if i.Big == nil && o.Big == nil {
+		if diff := i.Value - o.Value; (diff < i.Value) == (o.Value > 0) {
+			return NewInt(diff)
+		}
+	}
+	return NewBigInt(new(big.Int).Sub(i.big(), o.big()))
+}
+
+// Mul : return i * o.
+func (i *Int) Mul(o *Int) *Int {
+	if i.Big == nil && o.Big == nil {
+		if i.Value == 0 || o.Value == 0 {
+			return NewInt(0)
+		}
+		product := i.Value * o.Value
+		if product/o.Value == i.Value && !(o.Value == -1 && i.Value == math.MinInt) {
+			return NewInt(product)
+		}
+	}
+	return NewBigInt(new(big.Int).Mul(i.big(), o.big()))
+}
+
+// Quo : return i // o truncated towards zero, o must not be zero.
+func (i *Int) Quo(o *Int) *Int {
+	if i.Big == nil && o.Big == nil && !(o.Value == -1 && i.Value == math.MinInt) {
+		return NewInt(i.Value / o.Value)
+	}
+	return NewBigInt(new(big.Int).Quo(i.big(), o.big()))
+}
+
+// Rem : return i % o with the sign of i, o must not be zero.
+func (i *Int) Rem(o *Int) *Int {
+	if i.Big == nil && o.Big == nil {
+		return NewInt(i.Value % o.Value)
+	}
+	return NewBigInt(new(big.Int).Rem(i.big(), o.big()))
+}
+
+// Pow : return i ** o. A negative exponent gives the result truncated
+// towards zero, which is zero unless i is 1 or -1.
+func (i *Int) Pow(o *Int) Object {
+	if o.Value < 0 {
+		switch {
+		case i.Big == nil && i.Value == 0:
+			return NewError(DivisionByZeroError)
+		case i.Big == nil && i.Value == 1, i.Big == nil && i.Value == -1 && o.big().Bit(0) == 0:
+			return NewInt(1)
+		case i.Big == nil && i.Value == -1:
+			return NewInt(-1)
+		}
+		return NewInt(0)
+	}
+	if o.Big != nil {
+		return NewError("exponent %s too large", o.Inspect())
+	}
+	return NewBigInt(new(big.Int).Exp(i.big(), o.big(), nil))
+}
+
+// Lsh : return i << o, o must not be negative.
+func (i *Int) Lsh(o *Int) Object {
+	if o.Big != nil {
+		return NewError("shift count %s too large", o.Inspect())
+	}
+	if i.Big == nil && o.Value < 63 {
+		if shifted := i.Value << uint(o.Value); shifted>>uint(o.Value) == i.Value {
+			return NewInt(shifted)
+		}
+	}
+	return NewBigInt(new(big.Int).Lsh(i.big(), uint(o.Value)))
+}
+
+// Rsh : return i >> o rounded down, o must not be negative.
+func (i *Int) Rsh(o *Int) *Int {
+	if i.Big == nil {
+		return NewInt(i.Value >> uint(o.Value))
+	}
+	if o.Big != nil {
+		return NewInt(i.Big.Sign() >> 1)
+	}
+	return NewBigInt(new(big.Int).Rsh(i.Big, uint(o.Value)))
+}
+
+// Neg : return -i.
+func (i *Int) Neg() *Int {
+	if i.Big == nil && i.Value != math.MinInt {
+		return NewInt(-i.Value)
+	}
+	return NewBigInt(new(big.Int).Neg(i.big()))
+}
+
+// Cmp : return -1, 0 or +1 as i is less than, equal to or greater than o.
+func (i *Int) Cmp(o *Int) int {
+	if i.Big == nil && o.Big == nil {
+		switch {
+		case i.Value < o.Value:
+			return -1
+		case i.Value > o.Value:
+			return 1
+		}
+		return 0
+	}
+	return i.big().Cmp(o.big())
+}
+
+// bits : an estimate of the number of bits needed to hold i ** o or i << o,
+// so a result too large for the Size limit is never made.
+func (i *Int) bits(o *Int, pow bool) int {
+	if o.Big != nil || o.Value < 0 {
+		return 0
+	}
+	n := i.big().BitLen()
+	if !pow {
+		return n + o.Value
+	}
+	if n <= 1 {
+		return n
+	}
+	return powBits(n, int64(o.Value))
+}
+
+// powBits : the bits needed for the power e of a number of n bits, at most
+// math.MaxInt.
+func powBits(n int, e int64) int {
+	if e < 0 {
+		e = -e
+	}
+	if n > 0 && e > int64(math.MaxInt/n) {
+		return math.MaxInt
+	}
+	return n * int(e)
+}
+
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Methods needed to satisfy the Numeric interface:
 
@@ -49,7 +260,7 @@ func (i *Int) Abs() Object {
 	if i.Value >= 0 {
 		return i
 	}
-	return &Int{Value: -i.Value}
+	return i.Neg()
 }
 
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
@@ -57,6 +268,11 @@ func (i *Int) Abs() Object {
 
 // Hash : hash value of int
 func (i *Int) Hash() HashKey {
+	if i.Big != nil {
+		h := fnv.New64a()
+		h.Write([]byte(i.Big.String()))
+		return HashKey{Type: i.Type(), Value: h.Sum64()}
+	}
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
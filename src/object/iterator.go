@@ -0,0 +1,112 @@
+package object
+
+import "unicode/utf8"
+
+// Iterator : steps through a sequence of items, as a for loop does. Done must
+// be false before each call to Next. Iterators are objects so dito code can
+// hold them, see the iter, next and done builtins.
+type Iterator interface {
+	Object
+	// Done : report whether every item has been returned by Next.
+	Done() bool
+	// Next : return the next item or an Error.
+	Next() Object
+}
+
+// Collect : return an Array of the remaining items of an iterator, stopping
+// with a LimitError once it is too large for rt or rt is cancelled. rt may be
+// nil when there is no evaluation to limit.
+func Collect(rt *Runtime, it Iterator) Object {
+	items := []Object{}
+	for !it.Done() {
+		if rt != nil {
+			if err := rt.CheckSize(len(items) + 1); err != nil {
+				return err
+			}
+			if len(items)%checkEvery == 0 {
+				if err := rt.Cancelled(); err != nil {
+					return err
+				}
+			}
+		}
+		item := it.Next()
+		if item.Type() == ErrorType {
+			return item
+		}
+		items = append(items, item)
+	}
+	return NewArray(items, -1)
+}
+
+// convertIterator : the type conversions shared by iterators.
+func convertIterator(it Iterator, which TypeFlag) Object {
+	switch which {
+	case IteratorType:
+		return it
+	case ArrayType:
+		return Collect(nil, it)
+	case BoolType:
+		return TRUE
+	default:
+		return NewError(ConvertTypeError, it.Type(), which)
+	}
+}
+
+// SliceIterator : iterates the items of a slice in order.
+type SliceIterator struct {
+	items []Object
+	pos   int
+}
+
+// NewSliceIterator : return new initialized instance of the object.
+func NewSliceIterator(items []Object) *SliceIterator {
+	return &SliceIterator{items: items}
+}
+
+// Type : return objects type as a TypeFlag
+func (it *SliceIterator) Type() TypeFlag { return IteratorType }
+
+// Inspect : return a string representation of the objects value.
+func (it *SliceIterator) Inspect() string { return "<iterator>" }
+
+// ConvertType : return the conversion into the specified type
+func (it *SliceIterator) ConvertType(which TypeFlag) Object {
+	return convertIterator(it, which)
+}
+
+// Done : report whether every item has been returned by Next.
+func (it *SliceIterator) Done() bool { return it.pos >= len(it.items) }
+
+// Next : return the next item.
+func (it *SliceIterator) Next() Object {
+	item := it.items[it.pos]
+	it.pos++
+	return item
+}
+
+// StringIterator : iterates the characters of a string as Chars.
+type StringIterator struct {
+	value string
+	pos   int
+}
+
+// Type : return objects type as a TypeFlag
+func (it *StringIterator) Type() TypeFlag { return IteratorType }
+
+// Inspect : return a string representation of the objects value.
+func (it *StringIterator) Inspect() string { return "<iterator>" }
+
+// ConvertType : return the conversion into the specified type
+func (it *StringIterator) ConvertType(which TypeFlag) Object {
+	return convertIterator(it, which)
+}
+
+// Done : report whether every item has been returned by Next.
+func (it *StringIterator) Done() bool { return it.pos >= len(it.value) }
+
+// Next : return the next character.
+func (it *StringIterator) Next() Object {
+	r, size := utf8.DecodeRuneInString(it.value[it.pos:])
+	it.pos += size
+	return NewChar(r)
+}
@@ -8,6 +8,7 @@ import (
 // Lambda :
 type Lambda struct {
 	Parameters []*ast.Identifier
+	ParamTypes []string // see ParamTypes.
 	Expr       ast.Expression
 	Env        *Environment
 }
@@ -35,7 +36,7 @@ func (l *Lambda) Inspect() string {
 
 // NewLambda : return new initialised instance of the object.
 func NewLambda(params []*ast.Identifier, expr ast.Expression, env *Environment) *Lambda {
-	return &Lambda{Parameters: params, Env: env, Expr: expr}
+	return &Lambda{Parameters: params, ParamTypes: ParamTypes(params), Env: env, Expr: expr}
 }
 
 // ConvertType : return the conversion into the specified type
@@ -0,0 +1,35 @@
+package object
+
+import "fmt"
+
+// Module : the namespace of an imported file. Its attributes are the
+// variables set at the top level of the file.
+type Module struct {
+	Name string
+	Path string
+	Env  *Environment
+}
+
+// Type : return objects type as a TypeFlag
+func (m *Module) Type() TypeFlag { return ModuleType }
+
+// Inspect : return a string representation of the objects value.
+func (m *Module) Inspect() string { return fmt.Sprintf("<module %s>", m.Name) }
+
+// ConvertType : return the conversion into the specified type
+func (m *Module) ConvertType(which TypeFlag) Object {
+	return NewError(ConvertTypeError, m.Type(), which)
+}
+
+// GetAttr : return the value of a variable in the module.
+func (m *Module) GetAttr(name string) Object {
+	if val, ok := m.Env.Get(name); ok {
+		return val
+	}
+	return NewError(ModuleAttrError, m.Name, name)
+}
+
+// SetAttr : modules can only be changed by their own code.
+func (m *Module) SetAttr(name string, val Object) Object {
+	return NewError("cannot assign to attribute '%s' of module '%s'", name, m.Name)
+}
@@ -1,5 +1,7 @@
 package object
 
+import "dito/src/token"
+
 // Object : defines the interface for the objects used in the dito programming
 // language.
 type Object interface {
@@ -29,9 +31,8 @@ type Iterable interface {
 	Object
 	// Length : return the number of items in the iterable
 	Length() Object
-	// IterItems : return a iterable channel to loop through the items in
-	// order.
-	Iter() <-chan Object
+	// Iter : return an Iterator over the items in order.
+	Iter() Iterator
 	// GetItem : get item at location of the provided key.
 	GetItem(Object) Object
 	// SetItem : set item at location of the provided key.
@@ -63,6 +64,33 @@ type HashKey struct {
 	Value uint64
 }
 
+// AsHashable : return obj as a Hashable when it can be used as a hash key.
+// An Instance can only when all of its fields can, and not when it holds
+// itself.
+func AsHashable(obj Object) (Hashable, bool) {
+	return asHashable(obj, map[Object]bool{})
+}
+
+// asHashable : AsHashable within the instances seen.
+func asHashable(obj Object, seen map[Object]bool) (Hashable, bool) {
+	hashable, ok := obj.(Hashable)
+	inst, isInstance := obj.(*Instance)
+	if !ok || !isInstance {
+		return hashable, ok
+	}
+	if seen[inst] {
+		return nil, false
+	}
+	seen[inst] = true
+	defer delete(seen, inst)
+	for _, field := range inst.Fields {
+		if _, ok := asHashable(field, seen); !ok {
+			return nil, false
+		}
+	}
+	return hashable, true
+}
+
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Singleton objects :  Only one instance of these needs to be created.
 var (
@@ -88,3 +116,48 @@ func (rv *ReturnValue) Inspect() string { return rv.Value.Inspect() }
 func (rv *ReturnValue) ConvertType(which TypeFlag) Object {
 	return NewError("Argument to %s not supported, got %s", rv.Type(), which)
 }
+
+// TailCall : a call in tail position, returned to the function making it so
+// the call reuses its frame. Pos is where the call was made.
+type TailCall struct {
+	Fn   Object
+	Args []Object
+	Pos  token.Pos
+}
+
+// Type : return objects type as a string
+func (tc *TailCall) Type() TypeFlag { return TailCallType }
+
+// Inspect : return a string representation of the objects value.
+func (tc *TailCall) Inspect() string { return "<tail call>" }
+
+// ConvertType : return the conversion into the specified type
+func (tc *TailCall) ConvertType(which TypeFlag) Object {
+	return NewError("Argument to %s not supported, got %s", tc.Type(), which)
+}
+
+// LoopControl : returned by break and continue statements up to the loop
+// they belong to.
+type LoopControl struct{ Break bool }
+
+// Loop control singletons.
+var (
+	BREAK    = &LoopControl{Break: true}
+	CONTINUE = &LoopControl{Break: false}
+)
+
+// Type : return objects type as a string
+func (lc *LoopControl) Type() TypeFlag { return LoopControlType }
+
+// Inspect : return a string representation of the objects value.
+func (lc *LoopControl) Inspect() string {
+	if lc.Break {
+		return "break"
+	}
+	return "continue"
+}
+
+// ConvertType : return the conversion into the specified type
+func (lc *LoopControl) ConvertType(which TypeFlag) Object {
+	return NewError("Argument to %s not supported, got %s", lc.Type(), which)
+}
@@ -0,0 +1,196 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Range : the Ints from Start up to but not including Stop, counting by Step
+// which may be negative. Items are worked out from their index when needed,
+// so a Range takes the same memory whatever its length.
+type Range struct {
+	Start int
+	Stop  int
+	Step  int
+}
+
+// NewRange : return new initialized instance of the object. step must not be
+// zero, and the range must Fit.
+func NewRange(start, stop, step int) *Range {
+	return &Range{Start: start, Stop: stop, Step: step}
+}
+
+// Fits : report whether the number of items in the range fits in an int, a
+// longer range could not be measured or indexed.
+func (r *Range) Fits() bool { return r.count() <= math.MaxInt }
+
+// Type : return objects type as a TypeFlag
+func (r *Range) Type() TypeFlag { return RangeType }
+
+// Inspect : return a string representation of the objects value.
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+// ConvertType : return the conversion into the specified type
+func (r *Range) ConvertType(which TypeFlag) Object {
+	switch which {
+	case RangeType:
+		return r
+	case ArrayType:
+		return Collect(nil, r.Iter())
+	case BoolType:
+		return NewBool(r.len() > 0)
+	default:
+		return NewError(ConvertTypeError, r.Type(), which)
+	}
+}
+
+// count : the number of items in the range. Worked out unsigned, as the
+// distance between any two ints fits in a uint64.
+func (r *Range) count() uint64 {
+	if r.Step > 0 && r.Start < r.Stop {
+		return (uint64(r.Stop)-uint64(r.Start)-1)/r.stride() + 1
+	}
+	if r.Step < 0 && r.Start > r.Stop {
+		return (uint64(r.Start)-uint64(r.Stop)-1)/r.stride() + 1
+	}
+	return 0
+}
+
+// stride : the distance between items, the size of Step.
+func (r *Range) stride() uint64 {
+	if r.Step > 0 {
+		return uint64(r.Step)
+	}
+	return uint64(-(r.Step + 1)) + 1
+}
+
+// len : the number of items in a range which Fits.
+func (r *Range) len() int { return int(r.count()) }
+
+// at : the item at index i, which must be less than len. The product may
+// wrap around but the sum is an item so fits in an int.
+func (r *Range) at(i int) int { return r.Start + i*r.Step }
+
+// Equal : ranges are equal when they have the same items.
+func (r *Range) Equal(other *Range) bool {
+	n := r.len()
+	if n != other.len() {
+		return false
+	}
+	return n == 0 || r.Start == other.Start && (n == 1 || r.Step == other.Step)
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Methods needed to satisfy the Iterable interface:
+
+// Length : return the number of items in the Range.
+func (r *Range) Length() Object { return NewInt(r.len()) }
+
+// GetItem : return the item at the position provided by the index key.
+func (r *Range) GetItem(key Object) Object {
+	if idx, ok := key.(*Int); ok {
+		if idx.Value >= 0 && idx.Value < r.len() {
+			return NewInt(r.at(idx.Value))
+		}
+	}
+	return NewError("index error")
+}
+
+// SetItem : ranges can not be changed.
+func (r *Range) SetItem(key Object, val Object) Object {
+	return NewError("'%s' does not support item assignment", r.Type())
+}
+
+// Slice : return the Range of the items between the start and end indexes,
+// bounded like the slice of an Array.
+func (r *Range) Slice(start Object, end Object) Object {
+	startInt, ok := start.(*Int)
+	if !ok {
+		return NewError("slice start index type error.")
+	}
+	endInt, ok := end.(*Int)
+	if !ok {
+		return NewError("slice end index type error.")
+	}
+	if startInt.Value > endInt.Value {
+		return NewError("slice index error. start must be less than end index")
+	}
+	if endInt.Value < 0 || endInt.Value > r.len() {
+		return NewError("slice end index out of bounds error")
+	}
+	if startInt.Value < 0 || startInt.Value >= r.len() {
+		return NewError("slice start index out of bounds error")
+	}
+	first := r.at(startInt.Value)
+	if startInt.Value == endInt.Value {
+		return NewRange(first, first, r.Step)
+	}
+	// Stop at the item after the last, or just past the last when the item
+	// after it does not fit in an int.
+	last := r.at(endInt.Value - 1)
+	switch {
+	case r.Step > 0 && last > math.MaxInt-r.Step:
+		return NewRange(first, last+1, r.Step)
+	case r.Step < 0 && last < math.MinInt-r.Step:
+		return NewRange(first, last-1, r.Step)
+	}
+	return NewRange(first, last+r.Step, r.Step)
+}
+
+// Concat : ranges can not be concatenated.
+func (r *Range) Concat(other Object) Object {
+	return NewError("'%s' does not support concatenation", r.Type())
+}
+
+// Contains : is item one of the Ints in the range.
+func (r *Range) Contains(item Object) Object {
+	i, ok := item.(*Int)
+	if !ok || i.IsBig() {
+		return FALSE
+	}
+	var offset uint64
+	switch {
+	case r.Step > 0 && i.Value >= r.Start && i.Value < r.Stop:
+		offset = uint64(i.Value) - uint64(r.Start)
+	case r.Step < 0 && i.Value <= r.Start && i.Value > r.Stop:
+		offset = uint64(r.Start) - uint64(i.Value)
+	default:
+		return FALSE
+	}
+	return NewBool(offset%r.stride() == 0)
+}
+
+// Iter : return an Iterator over the items in order.
+func (r *Range) Iter() Iterator { return &RangeIterator{r: r, n: r.len()} }
+
+// RangeIterator : iterates the items of a Range.
+type RangeIterator struct {
+	r    *Range
+	i, n int
+}
+
+// Type : return objects type as a TypeFlag
+func (it *RangeIterator) Type() TypeFlag { return IteratorType }
+
+// Inspect : return a string representation of the objects value.
+func (it *RangeIterator) Inspect() string { return "<iterator " + it.r.Inspect() + ">" }
+
+// ConvertType : return the conversion into the specified type
+func (it *RangeIterator) ConvertType(which TypeFlag) Object {
+	return convertIterator(it, which)
+}
+
+// Done : report whether every item has been returned by Next.
+func (it *RangeIterator) Done() bool { return it.i >= it.n }
+
+// Next : return the next item.
+func (it *RangeIterator) Next() Object {
+	item := NewInt(it.r.at(it.i))
+	it.i++
+	return item
+}
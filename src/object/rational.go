@@ -0,0 +1,155 @@
+package object
+
+import (
+	"hash/fnv"
+	"math"
+	"math/big"
+)
+
+// Rational : an exact fraction of two Ints, always in lowest terms.
+type Rational struct {
+	Value *big.Rat
+}
+
+// NewRational : return new initialized instance of the object.
+func NewRational(value *big.Rat) *Rational { return &Rational{Value: value} }
+
+// Type : return objects type as a TypeFlag
+func (r *Rational) Type() TypeFlag { return RationalType }
+
+// Inspect : return a string representation of the objects value, like 1/3
+// or just 2 when it is a whole number.
+func (r *Rational) Inspect() string { return r.Value.RatString() }
+
+// ConvertType : return the conversion into the specified type
+func (r *Rational) ConvertType(which TypeFlag) Object {
+	switch which {
+	case RationalType:
+		return r
+	case IntType:
+		return NewBigInt(new(big.Int).Quo(r.Value.Num(), r.Value.Denom()))
+	case DecimalType:
+		return r.Decimal(DefaultDecimalContext)
+	case FloatType:
+		f, _ := r.Value.Float64()
+		return NewFloat(f)
+	case StringType:
+		return NewString(r.Inspect())
+	case BoolType:
+		return NewBool(r.Value.Sign() != 0)
+	default:
+		return NewError(ConvertTypeError, r.Type(), which)
+	}
+}
+
+// Decimal : the value as a Decimal, rounded to fit ctx when it has more
+// places.
+func (r *Rational) Decimal(ctx DecimalContext) *Decimal {
+	n := NewDecimal(new(big.Int).Set(r.Value.Num()), 0)
+	return n.Quo(NewDecimal(new(big.Int).Set(r.Value.Denom()), 0), ctx).(*Decimal)
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Arithmetic, used by the binary operators:
+
+// Add : return r + o.
+func (r *Rational) Add(o *Rational) *Rational {
+	return NewRational(new(big.Rat).Add(r.Value, o.Value))
+}
+
+// Sub : return r - o.
+func (r *Rational) Sub(o *Rational) *Rational {
+	return NewRational(new(big.Rat).Sub(r.Value, o.Value))
+}
+
+// Mul : return r * o.
+func (r *Rational) Mul(o *Rational) *Rational {
+	return NewRational(new(big.Rat).Mul(r.Value, o.Value))
+}
+
+// Quo : return r / o.
+func (r *Rational) Quo(o *Rational) Object {
+	if o.Value.Sign() == 0 {
+		return NewError(DivisionByZeroError)
+	}
+	return NewRational(new(big.Rat).Quo(r.Value, o.Value))
+}
+
+// IntQuo : return r // o, the quotient truncated towards zero.
+func (r *Rational) IntQuo(o *Rational) Object {
+	if o.Value.Sign() == 0 {
+		return NewError(DivisionByZeroError)
+	}
+	q := new(big.Rat).Quo(r.Value, o.Value)
+	return NewRational(new(big.Rat).SetInt(new(big.Int).Quo(q.Num(), q.Denom())))
+}
+
+// Rem : return r % o, with the sign of r.
+func (r *Rational) Rem(o *Rational) Object {
+	q := r.IntQuo(o)
+	if q, ok := q.(*Rational); ok {
+		return r.Sub(q.Mul(o))
+	}
+	return q
+}
+
+// Pow : return r ** o, o must be a whole number.
+func (r *Rational) Pow(o *Rational) Object {
+	if !o.Value.IsInt() || !o.Value.Num().IsInt64() || o.Value.Num().Int64() <= math.MinInt {
+		return NewError("exponent %s of a Rational must be a whole number", o.Inspect())
+	}
+	n := o.Value.Num().Int64()
+	if n < 0 {
+		if r.Value.Sign() == 0 {
+			return NewError(DivisionByZeroError)
+		}
+		n = -n
+		r = NewRational(new(big.Rat).Inv(r.Value))
+	}
+	exp := big.NewInt(n)
+	num := new(big.Int).Exp(r.Value.Num(), exp, nil)
+	return NewRational(new(big.Rat).SetFrac(num, new(big.Int).Exp(r.Value.Denom(), exp, nil)))
+}
+
+// bits : an estimate of the number of bits needed to hold r ** o.
+func (r *Rational) bits(o *Rational) int {
+	if !o.Value.IsInt() || !o.Value.Num().IsInt64() {
+		return 0
+	}
+	return powBits(r.Value.Num().BitLen()+r.Value.Denom().BitLen(), o.Value.Num().Int64())
+}
+
+// Cmp : return -1, 0 or +1 as r is less than, equal to or greater than o.
+func (r *Rational) Cmp(o *Rational) int { return r.Value.Cmp(o.Value) }
+
+// Neg : return -r.
+func (r *Rational) Neg() *Rational { return NewRational(new(big.Rat).Neg(r.Value)) }
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Methods needed to satisfy the Numeric interface:
+
+// Abs : return the absolute value of an number
+func (r *Rational) Abs() Object {
+	if r.Value.Sign() >= 0 {
+		return r
+	}
+	return r.Neg()
+}
+
+// ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+// Methods needed to satisfy the Hashable interface:
+
+// Hash : hash value of the fraction, see numberHash.
+func (r *Rational) Hash() HashKey { return numberHash(r.Value) }
+
+// numberHash : the hash of a number by its exact value, so Ints, Floats,
+// Decimals and Rationals of the same value are the same key. They all share
+// the key type of Int, whole numbers having the key of their Int.
+func numberHash(value *big.Rat) HashKey {
+	if value.IsInt() {
+		return NewBigInt(new(big.Int).Set(value.Num())).Hash()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(value.String()))
+	return HashKey{Type: IntType, Value: h.Sum64()}
+}
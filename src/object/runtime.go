@@ -0,0 +1,306 @@
+package object
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Runtime : the state shared by every Environment of one interpreter, the
+// builtins its code can call, its standard streams, where it finds modules and
+// the modules it has imported. Environments created without one use
+// DefaultRuntime.
+type Runtime struct {
+	Builtins map[string]*Builtin // nil for the default builtins.
+	Stdin    *File
+	Stdout   *File
+	Stderr   *File
+	Modules  *ModuleCache
+	// ImportPath : directories searched in order for modules which are not
+	// next to the file importing them.
+	ImportPath []string
+	Limits     Limits
+	// Decimal : the places and rounding of Decimal multiplication and
+	// division.
+	Decimal DecimalContext
+	// Capabilities : the groups of builtins code is allowed to use.
+	Capabilities Capability
+	// Call : set by a backend which cannot be called through the evaluator,
+	// used by builtins to call the functions of dito code.
+	Call func(fn Object, args []Object) Object
+
+	ctx       context.Context  // nil unless evaluating between Begin and its end.
+	suspended map[Stopper]bool // stopped when the evaluation finishes, see Suspend.
+	steps     int
+	depth     int64  // nested calls, atomic as a stopped generator returns from them on another goroutine.
+	tripped   *Error // the first limit exceeded by the evaluation.
+}
+
+// DefaultRuntime : the runtime of the process, using its standard streams.
+var DefaultRuntime = &Runtime{
+	Stdin:   STDIN,
+	Stdout:  STDOUT,
+	Stderr:  STDERR,
+	Modules: NewModuleCache(),
+	Decimal: DefaultDecimalContext,
+
+	ImportPath:   DefaultImportPath,
+	Capabilities: AllCapabilities,
+}
+
+// DefaultImportPath : where modules are searched for unless a Runtime is
+// given another path.
+var DefaultImportPath = []string{"lib"}
+
+// NewRuntime : return new initialized instance of the object using the given
+// streams for STDIN, STDOUT and STDERR. It has no capabilities.
+func NewRuntime(stdin io.Reader, stdout, stderr io.Writer) *Runtime {
+	return &Runtime{
+		Stdin:   NewFile("<stdin>", stdin, nil),
+		Stdout:  NewFile("<stdout>", nil, stdout),
+		Stderr:  NewFile("<stderr>", nil, stderr),
+		Modules: NewModuleCache(),
+		Decimal: DefaultDecimalContext,
+
+		ImportPath: DefaultImportPath,
+	}
+}
+
+// Capability : a group of builtins which reach outside of the interpreter,
+// only usable when the Runtime allows it.
+type Capability int
+
+// Capabilities a Runtime can allow, they can be combined with '|'.
+const (
+	CapStdio      Capability = 1 << iota // print and the STDIN, STDOUT, STDERR variables.
+	CapFilesystem                        // opening files.
+	CapTime                              // reading the clock and sleeping.
+	CapRandom                            // random numbers.
+
+	NoCapabilities  Capability = 0
+	AllCapabilities            = CapStdio | CapFilesystem | CapTime | CapRandom
+)
+
+var capabilityNames = []string{"stdio", "filesystem", "time", "random"}
+
+func (c Capability) String() string {
+	names := []string{}
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Allows : does the runtime allow code to use all of the capabilities c.
+func (rt *Runtime) Allows(c Capability) bool {
+	return rt.Capabilities&c == c
+}
+
+// PermissionError : the Kind of the errors raised when code uses a builtin
+// or variable its Runtime does not allow.
+const PermissionError = "PermissionError"
+
+// NewPermissionError : return the error for using name without c.
+func NewPermissionError(name string, c Capability) *Error {
+	err := NewError("permission denied: %s needs the %s capability", name, c)
+	err.Kind = PermissionError
+	return err
+}
+
+// ModuleCache : the modules imported by a runtime keyed by their path, so
+// each is only evaluated once.
+type ModuleCache struct {
+	mu      sync.Mutex
+	modules map[string]*Module
+	loading []string // paths of the modules being evaluated, in import order.
+}
+
+// NewModuleCache : return new initialized instance of the object.
+func NewModuleCache() *ModuleCache {
+	return &ModuleCache{modules: make(map[string]*Module)}
+}
+
+// Start : record that the module at path is being evaluated. Returns the
+// module if it has already been loaded, or the import chain if it is still
+// being evaluated.
+func (mc *ModuleCache) Start(path string) (*Module, []string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for i, p := range mc.loading {
+		if p == path {
+			return nil, append(append([]string{}, mc.loading[i:]...), path)
+		}
+	}
+	if module, ok := mc.modules[path]; ok {
+		return module, nil
+	}
+	mc.loading = append(mc.loading, path)
+	return nil, nil
+}
+
+// Finish : stop evaluating the module at path, caching it when not nil.
+func (mc *ModuleCache) Finish(path string, module *Module) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for i := len(mc.loading) - 1; i >= 0; i-- {
+		if mc.loading[i] == path {
+			mc.loading = append(mc.loading[:i], mc.loading[i+1:]...)
+			break
+		}
+	}
+	if module != nil {
+		mc.modules[path] = module
+	}
+}
+
+// Limits : bounds on the work done by one evaluation, zero is unlimited.
+type Limits struct {
+	Steps int           // nodes evaluated.
+	Depth int           // nested function calls.
+	Size  int           // elements of a collection or bytes of a string.
+	Time  time.Duration // wall clock time.
+}
+
+// checkEvery : how many steps pass between checks for cancellation.
+const checkEvery = 256
+
+// Begin : start an evaluation which is cancelled along with ctx, resetting
+// the counts checked against Limits. The returned function must be called
+// once the evaluation finishes. An evaluation begun during another, by a
+// builtin calling back into the interpreter, shares its limits.
+func (rt *Runtime) Begin(ctx context.Context) context.CancelFunc {
+	if rt.ctx != nil {
+		return func() {}
+	}
+	var cancel context.CancelFunc
+	if rt.Limits.Time > 0 {
+		ctx, cancel = context.WithTimeout(ctx, rt.Limits.Time)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	rt.ctx, rt.steps, rt.tripped = ctx, 0, nil
+	return func() {
+		cancel()
+		for len(rt.suspended) > 0 {
+			for s := range rt.suspended {
+				delete(rt.suspended, s)
+				s.Stop()
+			}
+		}
+		rt.ctx = nil
+	}
+}
+
+// Stopper : a computation suspended part way through, such as a generator,
+// which has to be stopped if it is not run to its end.
+type Stopper interface {
+	Stop()
+}
+
+// Suspend : record s as suspended until Resumed. Whatever is still suspended
+// when the evaluation finishes is stopped, so it does not outlive it. Outside
+// of an evaluation nothing is recorded.
+func (rt *Runtime) Suspend(s Stopper) {
+	if rt.ctx == nil {
+		return
+	}
+	if rt.suspended == nil {
+		rt.suspended = make(map[Stopper]bool)
+	}
+	rt.suspended[s] = true
+}
+
+// Resumed : s has run to its end or been stopped.
+func (rt *Runtime) Resumed(s Stopper) { delete(rt.suspended, s) }
+
+// Context : the context of the current evaluation.
+func (rt *Runtime) Context() context.Context {
+	if rt.ctx == nil {
+		return context.Background()
+	}
+	return rt.ctx
+}
+
+// Step : count the evaluation of a node, returning a LimitError once there
+// have been too many or the evaluation has been cancelled. Once any limit has
+// been exceeded every later Step fails too.
+func (rt *Runtime) Step() *Error {
+	if rt.ctx == nil {
+		return nil
+	}
+	if rt.tripped != nil {
+		return rt.tripped
+	}
+	rt.steps++
+	if rt.Limits.Steps > 0 && rt.steps > rt.Limits.Steps {
+		return rt.limitError("step limit of %d exceeded", rt.Limits.Steps)
+	}
+	if rt.steps%checkEvery == 0 {
+		return rt.Cancelled()
+	}
+	return nil
+}
+
+// Cancelled : return a LimitError if the evaluation has been cancelled or
+// has run out of time.
+func (rt *Runtime) Cancelled() *Error {
+	if rt.ctx == nil {
+		return nil
+	}
+	switch rt.ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		if rt.Limits.Time > 0 {
+			return rt.limitError("time limit of %s exceeded", rt.Limits.Time)
+		}
+	}
+	return rt.limitError("evaluation cancelled: %s", rt.ctx.Err())
+}
+
+// MaxDepth : the deepest calls can be nested by the evaluator whatever the
+// Limits, far enough below the size of the Go stack that deep recursion
+// raises a RecursionError rather than crashing the process.
+const MaxDepth = 10000
+
+// Enter : count a function call, returning a LimitError when calls are
+// nested deeper than Limits.Depth and a RecursionError when deeper than
+// MaxDepth. Every successful Enter must be followed by a Leave.
+func (rt *Runtime) Enter() *Error {
+	depth := int(atomic.LoadInt64(&rt.depth))
+	if rt.ctx != nil && rt.Limits.Depth > 0 && depth >= rt.Limits.Depth {
+		return rt.limitError("call depth limit of %d exceeded", rt.Limits.Depth)
+	}
+	if depth >= MaxDepth {
+		return NewRecursionError()
+	}
+	atomic.AddInt64(&rt.depth, 1)
+	return nil
+}
+
+// Leave : count the return from a function call.
+func (rt *Runtime) Leave() { atomic.AddInt64(&rt.depth, -1) }
+
+// CheckSize : return a LimitError if a collection of size n is too large
+// to be created.
+func (rt *Runtime) CheckSize(n int) *Error {
+	if rt.Limits.Size > 0 && n > rt.Limits.Size {
+		return rt.limitError("size limit of %d exceeded, got %d", rt.Limits.Size, n)
+	}
+	return nil
+}
+
+// limitError : return a LimitError, recording it as tripped when evaluating.
+func (rt *Runtime) limitError(format string, a ...interface{}) *Error {
+	err := NewError(format, a...)
+	err.Kind = LimitError
+	if rt.ctx != nil && rt.tripped == nil {
+		rt.tripped = err
+	}
+	return err
+}
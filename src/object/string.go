@@ -1,15 +1,17 @@
 package object
 
 import (
-	"fmt"
 	"hash/fnv"
+	"math/big"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // String : builtin string type
 type String struct {
-	Value string
+	Value  string
+	Frozen bool
 }
 
 // Type : return objects type as a TypeFlag
@@ -31,14 +33,30 @@ func (s *String) ConvertType(which TypeFlag) Object {
 			return NewFloat(f)
 		}
 	case IntType:
-		if i, err := strconv.Atoi(s.Value); err == nil {
-			return NewInt(i)
+		if i, ok := ParseInt(s.Value, 10); ok {
+			return i
+		}
+	case DecimalType:
+		if d, ok := ParseDecimal(s.Value); ok {
+			return d
+		}
+	case RationalType:
+		// without exponents, whose value could be too large to make.
+		if strings.ContainsAny(s.Value, "eEpP") {
+			break
+		}
+		if r, ok := new(big.Rat).SetString(s.Value); ok {
+			return NewRational(r)
+		}
+	case CharType:
+		if r, size := utf8.DecodeRuneInString(s.Value); size > 0 && size == len(s.Value) {
+			return NewChar(r)
 		}
 	case ArrayType:
-		n := len(s.Value)
-		a := &Array{Elements: make([]Object, n), Len: n}
-		for i, s := range s.Value {
-			a.Elements[i] = &Char{Value: byte(s)}
+		runes := []rune(s.Value)
+		a := &Array{Elements: make([]Object, len(runes)), Len: len(runes)}
+		for i, r := range runes {
+			a.Elements[i] = NewChar(r)
 		}
 		return a
 	case BoolType:
@@ -50,16 +68,19 @@ func (s *String) ConvertType(which TypeFlag) Object {
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Methods needed to satisfy the Iterable interface:
 
-// Length : return the number of items in the String. An item is an ascii char
-// like in C strings.
-func (s *String) Length() Object { return &Int{Value: len(s.Value)} }
+// Length : return the number of items in the String. An item is a Char, a
+// unicode code point, however many bytes it takes.
+func (s *String) Length() Object { return NewInt(utf8.RuneCountInString(s.Value)) }
 
 // GetItem : return the char at index
 func (s *String) GetItem(key Object) Object {
-	if index, ok := key.(*Int); ok {
-		size := len(s.Value)
-		if index.Value >= 0 && index.Value < size {
-			return &Char{Value: byte(s.Value[index.Value])}
+	if index, ok := key.(*Int); ok && index.Value >= 0 {
+		i := 0
+		for _, r := range s.Value {
+			if i == index.Value {
+				return NewChar(r)
+			}
+			i++
 		}
 	}
 	// TODO : give better error.
@@ -68,27 +89,32 @@ func (s *String) GetItem(key Object) Object {
 
 // SetItem : set item at index except char or a string.
 func (s *String) SetItem(key Object, val Object) Object {
+	if s.Frozen {
+		return frozenError(s.Type().String())
+	}
 	index, ok := key.(*Int)
 	if !ok {
 		return NewError("index error")
 	}
-	size := len(s.Value)
-	if index.Value < 0 || index.Value >= size {
+	tmp := []rune(s.Value)
+	if index.Value < 0 || index.Value >= len(tmp) {
 		return NewError("index error")
 	}
-	tmp := []byte(s.Value)
 	switch v := val.(type) {
 	case *Char:
 		tmp[index.Value] = v.Value
 		s.Value = string(tmp)
 		return nil
 	case *String:
-		if len(v.Value) > 1 {
+		r, size := utf8.DecodeRuneInString(v.Value)
+		if size < len(v.Value) {
 			return NewError("Index Assignment Error. Length of value too long")
 		}
-		tmp[index.Value] = v.Value[0]
+		if size == 0 {
+			return NewError("Index Assignment Error. Value is empty")
+		}
+		tmp[index.Value] = r
 		s.Value = string(tmp)
-		fmt.Printf(s.Value)
 		return nil
 	default:
 		return NewError("Index Assignment Error")
@@ -108,37 +134,39 @@ func (s *String) Slice(start Object, end Object) Object {
 	if startInt.Value > endInt.Value {
 		return NewError("slice index error. start must be less than end index")
 	}
-	if endInt.Value < 0 || endInt.Value > len(s.Value) {
+	runes := []rune(s.Value)
+	if endInt.Value < 0 || endInt.Value > len(runes) {
 		return NewError("slice end index out of bounds error")
 	}
-	if startInt.Value < 0 || startInt.Value >= len(s.Value) {
+	if startInt.Value < 0 || startInt.Value >= len(runes) {
 		return NewError("slice start index out of bounds error")
 	}
-	slice := s.Value[startInt.Value:endInt.Value]
-	return NewString(slice)
+	slice := runes[startInt.Value:endInt.Value]
+	return NewString(string(slice))
 }
 
 // Concat : Add item to the current string creating a new string.
 func (s *String) Concat(other Object) Object {
-	return NewString(s.Value + other.(*String).Value)
+	o, ok := other.(*String)
+	if !ok {
+		return NewError(ConcatTypeError, s.Type(), other.Type())
+	}
+	return NewString(s.Value + o.Value)
 }
 
-// Contains :
+// Contains : is sub a substring, or a Char, of the string.
 func (s *String) Contains(sub Object) Object {
-	return NewBool(strings.Contains(s.Value, sub.(*String).Value))
+	switch sub := sub.(type) {
+	case *String:
+		return NewBool(strings.Contains(s.Value, sub.Value))
+	case *Char:
+		return NewBool(strings.ContainsRune(s.Value, sub.Value))
+	}
+	return FALSE
 }
 
-// Iter : loop through items elements in order.
-func (s *String) Iter() <-chan Object {
-	ch := make(chan Object)
-	go func() {
-		for _, item := range s.Value {
-			ch <- NewString(string(item))
-		}
-		close(ch)
-	}()
-	return ch
-}
+// Iter : return an Iterator over the characters in order.
+func (s *String) Iter() Iterator { return &StringIterator{value: s.Value} }
 
 // ''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
 // Methods needed to satisfy the Hashable interface:
@@ -0,0 +1,196 @@
+package object
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// Attributable : objects with named attributes accessed with a '.'.
+type Attributable interface {
+	Object
+	// GetAttr : return the attribute or an Error if it does not exist.
+	GetAttr(name string) Object
+	// SetAttr : set the attribute returning an Error on failure.
+	SetAttr(name string, val Object) Object
+}
+
+// Struct : a user defined type declared with a struct statement. Calling it
+// constructs an Instance from a value for each of its fields.
+type Struct struct {
+	Name    string
+	Fields  []string
+	Methods map[string]Object
+}
+
+// NewStruct : return new initialized instance of the object.
+func NewStruct(name string, fields []string) *Struct {
+	return &Struct{Name: name, Fields: fields, Methods: make(map[string]Object)}
+}
+
+// Type : return objects type as a TypeFlag
+func (s *Struct) Type() TypeFlag { return StructType }
+
+// Inspect : return a string representation of the objects value.
+func (s *Struct) Inspect() string { return fmt.Sprintf("<struct %s>", s.Name) }
+
+// ConvertType : return the conversion into the specified type
+func (s *Struct) ConvertType(which TypeFlag) Object {
+	return NewError(ConvertTypeError, s.Type(), which)
+}
+
+// New : construct an instance, args are the values of the fields in the
+// order they were declared.
+func (s *Struct) New(args []Object) Object {
+	if len(args) != len(s.Fields) {
+		return NewError(InvalidArgLenError, s.Name, len(s.Fields), len(args))
+	}
+	fields := make([]Object, len(args))
+	copy(fields, args)
+	return &Instance{Struct: s, Fields: fields}
+}
+
+func (s *Struct) field(name string) int {
+	for i, f := range s.Fields {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Instance : a value of a user defined Struct type.
+type Instance struct {
+	Struct *Struct
+	Fields []Object
+	Frozen bool
+}
+
+// Type : return objects type as a TypeFlag
+func (i *Instance) Type() TypeFlag { return InstanceType }
+
+// Inspect : return a string representation of the objects value.
+func (i *Instance) Inspect() string { return inspectIn(i, map[Object]bool{}) }
+
+func (i *Instance) inspect(seen map[Object]bool) string {
+	var out bytes.Buffer
+	out.WriteString(i.Struct.Name + "(")
+	for idx, name := range i.Struct.Fields {
+		if idx > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(name + "=" + inspectIn(i.Fields[idx], seen))
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// ConvertType : return the conversion into the specified type
+func (i *Instance) ConvertType(which TypeFlag) Object {
+	switch which {
+	case InstanceType:
+		return i
+	case StringType:
+		return NewString(i.Inspect())
+	case BoolType:
+		return TRUE
+	default:
+		return NewError(ConvertTypeError, i.Struct.Name, which)
+	}
+}
+
+// GetAttr : return the value of a field or a method bound to the instance.
+func (i *Instance) GetAttr(name string) Object {
+	if idx := i.Struct.field(name); idx >= 0 {
+		return i.Fields[idx]
+	}
+	if fn, ok := i.Struct.Methods[name]; ok {
+		return &BoundMethod{Receiver: i, Name: name, Fn: fn}
+	}
+	return NewError("'%s' has no attribute '%s'", i.Struct.Name, name)
+}
+
+// SetAttr : set the value of a field.
+func (i *Instance) SetAttr(name string, val Object) Object {
+	idx := i.Struct.field(name)
+	if idx < 0 {
+		return NewError("'%s' has no field '%s'", i.Struct.Name, name)
+	}
+	if i.Frozen {
+		return frozenError(i.Struct.Name)
+	}
+	i.Fields[idx] = val
+	return NONE
+}
+
+// Equal : instances are equal when they are of the same Struct and all their
+// fields are equal by '=='.
+func (i *Instance) Equal(other *Instance) bool {
+	return i.equal(other, map[[2]*Instance]bool{})
+}
+
+// equal : Equal within the pairs of instances seen, which are taken to be
+// equal so instances holding themselves are compared without recursing
+// forever.
+func (i *Instance) equal(other *Instance, seen map[[2]*Instance]bool) bool {
+	if i == other || seen[[2]*Instance{i, other}] {
+		return true
+	}
+	if i.Struct != other.Struct {
+		return false
+	}
+	seen[[2]*Instance{i, other}] = true
+	op := BinaryOps["=="]
+	for idx, field := range i.Fields {
+		a, aOk := field.(*Instance)
+		b, bOk := other.Fields[idx].(*Instance)
+		if aOk && bOk {
+			if !a.equal(b, seen) {
+				return false
+			}
+		} else if op.EvalBinary(nil, field, other.Fields[idx]) != TRUE {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash : hash of the struct name and the hash of each field. Only called
+// on an instance AsHashable accepts, so every field is hashable.
+func (i *Instance) Hash() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(i.Struct.Name))
+	buf := make([]byte, 8)
+	for _, field := range i.Fields {
+		binary.LittleEndian.PutUint64(buf, field.(Hashable).Hash().Value)
+		h.Write(buf)
+	}
+	return HashKey{Type: i.Type(), Value: h.Sum64()}
+}
+
+// BoundMethod : a method of an Instance. Calling it passes the instance as the
+// first argument of Fn.
+type BoundMethod struct {
+	Receiver Object
+	Name     string
+	Fn       Object
+}
+
+// Type : return objects type as a TypeFlag
+func (bm *BoundMethod) Type() TypeFlag { return bm.Fn.Type() }
+
+// Inspect : return a string representation of the objects value.
+func (bm *BoundMethod) Inspect() string {
+	return fmt.Sprintf("<method %s>", bm.Name)
+}
+
+// ConvertType : return the conversion into the specified type
+func (bm *BoundMethod) ConvertType(which TypeFlag) Object {
+	return NewError("Argument to %s not supported, got %s", bm.Type(), which)
+}
+
+// Args : the arguments to call Fn with.
+func (bm *BoundMethod) Args(args []Object) []Object {
+	return append([]Object{bm.Receiver}, args...)
+}
@@ -8,6 +8,8 @@ type TypeFlag int
 const (
 	CharType TypeFlag = iota
 	IntType
+	DecimalType
+	RationalType
 	FloatType
 	BoolType
 	StringType
@@ -20,11 +22,31 @@ const (
 	FunctionType
 	DictType
 	FileType
+	ExceptionType
+	LoopControlType
+	StructType
+	InstanceType
+	ModuleType
+	TailCallType
+	IteratorType
+	RangeType
 )
 
 func (t TypeFlag) String() string { return typeName[t] }
 
+// ParseTypeFlag : the TypeFlag with the name given.
+func ParseTypeFlag(name string) (TypeFlag, bool) {
+	for i, n := range typeName {
+		if n == name {
+			return TypeFlag(i), true
+		}
+	}
+	return 0, false
+}
+
 var typeName = [...]string{
-	"Char", "Int", "Float", "Bool", "String", "Array",
+	"Char", "Int", "Decimal", "Rational", "Float", "Bool", "String", "Array",
 	"None", "Error", "Return", "Lambda", "Builtin", "Function", "Dict", "File",
+	"Exception", "LoopControl", "Struct", "Instance", "Module", "TailCall",
+	"Iterator", "Range",
 }
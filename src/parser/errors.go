@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"dito/src/ast"
 	"dito/src/token"
 	"fmt"
 	"io"
@@ -27,9 +28,9 @@ type ParseError struct {
 }
 
 func (pe *ParseError) String() string {
-	return fmt.Sprintf(
-		"Traceback line %d column %d:\n%*s%s\n%*s%s\n%s\n",
-		pe.lineno, pe.column, 4, " ", pe.lineTrace, 4+pe.column, " ", "^ Is your problem here?",
+	return fmt.Sprintf("Traceback line %d column %d:\n%s%s\n",
+		pe.lineno, pe.column,
+		token.FormatTrace(pe.lineTrace, pe.column, "^ Is your problem here?"),
 		pe.message)
 }
 
@@ -61,6 +62,11 @@ func (p *Parser) genericError(t string, e error) {
 	p.errors = append(p.errors, p.newError(msg))
 }
 
+func (p *Parser) charError() {
+	msg := fmt.Sprintf("Char literal '%s' must be a single character", p.currentLiteral)
+	p.errors = append(p.errors, p.newError(msg))
+}
+
 func (p *Parser) peekError(t token.Token) {
 	msg := fmt.Sprintf("Expected next token is '%s'. got '%s' instead",
 		t, p.peekLiteral)
@@ -71,3 +77,18 @@ func (p *Parser) noParseFnError(t token.Token) {
 	msg := fmt.Sprintf("No parse function found for '%s'", t)
 	p.errors = append(p.errors, p.newError(msg))
 }
+
+func (p *Parser) outsideLoopError(t token.Token) {
+	msg := fmt.Sprintf("'%s' outside loop", t)
+	p.errors = append(p.errors, p.newError(msg))
+}
+
+func (p *Parser) outsideFunctionError(t token.Token) {
+	msg := fmt.Sprintf("'%s' outside function", t)
+	p.errors = append(p.errors, p.newError(msg))
+}
+
+func (p *Parser) assignmentError(exp ast.Expression) {
+	msg := fmt.Sprintf("Cannot assign to '%s'", exp)
+	p.errors = append(p.errors, p.newError(msg))
+}
@@ -11,7 +11,9 @@ import (
 	"dito/src/ast"
 	"dito/src/scanner"
 	"dito/src/token"
+	"math/big"
 	"strconv"
+	"unicode/utf8"
 )
 
 type (
@@ -36,7 +38,13 @@ type Parser struct {
 	peekLiteral    string
 	currentLine    int
 	peekTokenLine  int
+	currentPos     token.Pos
+	currentEnd     token.Pos
+	peekPos        token.Pos
+	peekEnd        token.Pos
 	openParen      bool
+	loopDepth      int           // number of loops around the current statement.
+	function       *ast.Function // the function the current statement is in.
 	prefixParseFns map[token.Token]prefixParseFn
 	infixParseFns  map[token.Token]infixParseFn
 }
@@ -64,6 +72,7 @@ func New(s *scanner.Scanner) *Parser {
 		token.LBRACKET: p.arrayLiteral,
 		token.LPAREN:   p.groupedExpression,
 		token.STRING:   p.stringLiteral,
+		token.CHAR:     p.charLiteral,
 		token.LBRACE:   p.dictLiteral,
 	}
 
@@ -93,6 +102,7 @@ func New(s *scanner.Scanner) *Parser {
 		token.OR:       p.infixExpression,
 		token.LPAREN:   p.callExpression,
 		token.LBRACKET: p.indexExpression,
+		token.PERIOD:   p.attributeExpression,
 		token.IF:       p.ifElseExpression,
 	}
 	// twice to fill current and peek token.
@@ -130,7 +140,26 @@ func (p *Parser) currentTokenIs(t token.Token) bool {
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
 	p.currentLiteral = p.peekLiteral
+	p.currentPos = p.peekPos
+	p.currentEnd = p.peekEnd
 	p.peekToken, p.peekLiteral, p.currentLine = p.scanner.NextToken()
+	p.peekPos = p.scanner.Pos()
+	p.peekEnd = p.scanner.End()
+}
+
+// span : the span of a node starting at start and ending with the current
+// token.
+func (p *Parser) span(start token.Pos) ast.Span {
+	return ast.Span{Start: start, Stop: p.currentEnd}
+}
+
+// startOf : the start position of a node, falling back to the current token
+// when a parse error left the node missing.
+func (p *Parser) startOf(node ast.Node) token.Pos {
+	if node == nil {
+		return p.currentPos
+	}
+	return node.Pos()
 }
 
 // check to see if there is a newline, EOF or Semicolon token or complain.
@@ -170,11 +199,19 @@ func (p *Parser) ParseProgram() *ast.Program {
 // statement:
 //     assignmentStatement
 //     indexAssignmentStatement
+//     attributeAssignmentStatement
 //     expressionStatement
 //     functionStatement
+//     structStatement
 //     returnStatement
+//     yieldStatement
+//     nonlocalStatement
 //     forStatement
 //     importStatement
+//     tryStatement
+//     raiseStatement
+//     breakStatement
+//     continueStatement
 func (p *Parser) statement() ast.Statement {
 	switch p.currentToken {
 	case token.LET:
@@ -183,27 +220,47 @@ func (p *Parser) statement() ast.Statement {
 		if p.peekToken.IsAssignmentOp() {
 			return p.reAssignStatement()
 		}
-		if p.peekTokenIs(token.LBRACKET) {
-			idxExp := p.expression(token.LOWEST)
+		if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.PERIOD) {
+			exp := p.expression(token.LOWEST)
 			if !p.peekToken.IsAssignmentOp() {
 				return &ast.ExpressionStatement{
+					Span:       p.span(p.startOf(exp)),
 					Token:      token.LBRACE,
-					Expression: idxExp,
+					Expression: exp,
 				}
 			}
-			return p.indexAssignmentStatement(idxExp.(*ast.IndexExpression))
+			switch exp := exp.(type) {
+			case *ast.IndexExpression:
+				return p.indexAssignmentStatement(exp)
+			case *ast.AttributeExpression:
+				return p.attributeAssignmentStatement(exp)
+			}
+			p.assignmentError(exp)
+			return nil
 		}
 		return p.expressionStatement()
 	case token.DEF:
 		return p.functionStatement()
+	case token.STRUCT:
+		return p.structStatement()
 	case token.RETURN:
 		return p.returnStatement()
+	case token.YIELD:
+		return p.yieldStatement()
+	case token.NONLOCAL:
+		return p.nonlocalStatement()
 	case token.IF:
 		return p.ifElseStatement()
 	case token.FOR:
 		return p.forStatement()
-	case token.IMPORT:
+	case token.IMPORT, token.FROM:
 		return p.importStatement()
+	case token.TRY:
+		return p.tryStatement()
+	case token.RAISE:
+		return p.raiseStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.loopControlStatement()
 	default:
 		return p.expressionStatement()
 	}
@@ -213,16 +270,111 @@ func (p *Parser) statement() ast.Statement {
 //     'return' expression
 func (p *Parser) returnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.currentToken}
+	start := p.currentPos
+	p.nextToken()
+	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(start)
+	return stmt
+}
+
+// yieldStatement:
+//     'yield' expression
+func (p *Parser) yieldStatement() *ast.YieldStatement {
+	if p.function == nil {
+		p.outsideFunctionError(p.currentToken)
+	} else {
+		// a function containing yield is a generator.
+		p.function.Generator = true
+	}
+	stmt := &ast.YieldStatement{Token: p.currentToken}
+	start := p.currentPos
+	p.nextToken()
+	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(start)
+	return stmt
+}
+
+// nonlocalStatement:
+//     'nonlocal' identifier (',' identifier)*
+func (p *Parser) nonlocalStatement() *ast.NonlocalStatement {
+	if p.function == nil {
+		p.outsideFunctionError(p.currentToken)
+	}
+	stmt := &ast.NonlocalStatement{Token: p.currentToken}
+	start := p.currentPos
+	for {
+		if !p.expectPeek(token.IDVAL) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, p.identifier().(*ast.Identifier))
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	stmt.Span = p.span(start)
+	return stmt
+}
+
+// breakStatement:
+//     'break'
+// continueStatement:
+//     'continue'
+func (p *Parser) loopControlStatement() ast.Statement {
+	if p.loopDepth == 0 {
+		p.outsideLoopError(p.currentToken)
+		return nil
+	}
+	span := ast.Span{Start: p.currentPos, Stop: p.currentEnd}
+	if p.currentTokenIs(token.BREAK) {
+		return &ast.BreakStatement{Span: span, Token: p.currentToken}
+	}
+	return &ast.ContinueStatement{Span: span, Token: p.currentToken}
+}
+
+// raiseStatement:
+//     'raise' expression
+func (p *Parser) raiseStatement() *ast.RaiseStatement {
+	stmt := &ast.RaiseStatement{Token: p.currentToken}
+	start := p.currentPos
 	p.nextToken()
 	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(start)
+	return stmt
+}
+
+// tryStatement:
+//     'try' '{' blockStatement '}' 'catch' '{' blockStatement '}'
+//     'try' '{' blockStatement '}' 'catch' identifier '{' blockStatement '}'
+func (p *Parser) tryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{Token: p.currentToken}
+	start := p.currentPos
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.blockStatement()
+	if !p.expectPeek(token.CATCH) {
+		return nil
+	}
+	if p.peekTokenIs(token.IDVAL) {
+		p.nextToken()
+		stmt.Name = p.identifier().(*ast.Identifier)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Handler = p.blockStatement()
+	stmt.Span = p.span(start)
 	return stmt
 }
 
 // assignmentStatement:
 //     let identifier = expression
 //     | let mux identifier = expression
+//     | let identifier ':' identifier = expression
 func (p *Parser) assignmentStatement() *ast.AssignmentStatement {
 	stmt := &ast.AssignmentStatement{}
+	start := p.currentPos
 	if p.peekTokenIs(token.MUT) {
 		p.nextToken()
 	}
@@ -230,12 +382,16 @@ func (p *Parser) assignmentStatement() *ast.AssignmentStatement {
 	if !p.expectPeek(token.IDVAL) {
 		return nil
 	}
-	stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentLiteral}
+	stmt.Name = p.identifier().(*ast.Identifier)
+	if !p.typeAnnotation(stmt.Name) {
+		return nil
+	}
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 	p.nextToken() // this could be anything.
 	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(start)
 	return stmt
 }
 
@@ -244,11 +400,12 @@ func (p *Parser) assignmentStatement() *ast.AssignmentStatement {
 func (p *Parser) reAssignStatement() *ast.ReAssignStatement {
 	stmt := &ast.ReAssignStatement{
 		Token: p.peekToken,
-		Name:  &ast.Identifier{Token: p.currentToken, Value: p.currentLiteral},
+		Name:  p.identifier().(*ast.Identifier),
 	}
 	p.nextToken()
 	p.nextToken()
 	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(stmt.Name.Pos())
 	return stmt
 }
 
@@ -260,6 +417,19 @@ func (p *Parser) indexAssignmentStatement(idxExp *ast.IndexExpression) *ast.Inde
 	stmt.Token = p.currentToken
 	p.nextToken()
 	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(p.startOf(idxExp))
+	return stmt
+}
+
+// attributeAssignmentStatement:
+//     expression '.' identifier assignmentOperator expression
+func (p *Parser) attributeAssignmentStatement(attrExp *ast.AttributeExpression) *ast.AttributeAssignmentStatement {
+	stmt := &ast.AttributeAssignmentStatement{AttrExp: attrExp}
+	p.nextToken()
+	stmt.Token = p.currentToken
+	p.nextToken()
+	stmt.Value = p.expression(token.LOWEST)
+	stmt.Span = p.span(p.startOf(attrExp))
 	return stmt
 }
 
@@ -267,7 +437,9 @@ func (p *Parser) indexAssignmentStatement(idxExp *ast.IndexExpression) *ast.Inde
 //     expression
 func (p *Parser) expressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.currentToken}
+	start := p.currentPos
 	stmt.Expression = p.expression(token.LOWEST)
+	stmt.Span = p.span(start)
 	return stmt
 }
 
@@ -276,6 +448,7 @@ func (p *Parser) expressionStatement() *ast.ExpressionStatement {
 //     'if' expression '{' blockStatement '}' 'else' '{' blockStatement '}'
 func (p *Parser) ifElseStatement() *ast.IfStatement {
 	expression := &ast.IfStatement{Token: p.currentToken}
+	start := p.currentPos
 	p.nextToken()
 	expression.Condition = p.expression(token.LOWEST)
 	if !p.expectPeek(token.LBRACE) {
@@ -289,13 +462,16 @@ func (p *Parser) ifElseStatement() *ast.IfStatement {
 		}
 		expression.Alternative = p.blockStatement()
 	}
+	expression.Span = p.span(start)
 	return expression
 }
 
 // functionStatement
 //     'func' identifier '(' functionParameters ')' '{' blockStatement '}'
+//     'func' identifier '(' functionParameters ')' '->' identifier '{' blockStatement '}'
 func (p *Parser) functionStatement() *ast.Function {
 	fn := &ast.Function{Token: p.currentToken}
+	start := p.currentPos
 	if !p.expectPeek(token.IDVAL) {
 		return nil
 	}
@@ -304,18 +480,75 @@ func (p *Parser) functionStatement() *ast.Function {
 		return nil
 	}
 	fn.Parameters = p.functionParameters()
+	if p.peekTokenIs(token.RARROW) {
+		p.nextToken()
+		if !p.expectPeek(token.IDVAL) {
+			return nil
+		}
+		fn.ReturnType = p.identifier().(*ast.Identifier)
+	}
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
+	// loops around a function do not enclose the statements of its body.
+	loopDepth, function := p.loopDepth, p.function
+	p.loopDepth, p.function = 0, fn
 	fn.Body = p.blockStatement()
+	p.loopDepth, p.function = loopDepth, function
+	fn.Span = p.span(start)
 	return fn
 }
 
+// structStatement:
+//     'struct' identifier '{' structBody '}'
+// structBody:
+//     identifier ',' ... stmtend structBody
+//     functionStatement stmtend structBody
+func (p *Parser) structStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.currentToken}
+	start := p.currentPos
+	if !p.expectPeek(token.IDVAL) {
+		return nil
+	}
+	stmt.Name = p.identifier().(*ast.Identifier)
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+	for !p.currentTokenIs(token.RBRACE) {
+		switch p.currentToken {
+		case token.NEWLINE, token.SEMI:
+		case token.IDVAL:
+			stmt.Fields = append(stmt.Fields, p.identifier().(*ast.Identifier))
+			for p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+				if !p.expectPeek(token.IDVAL) {
+					return nil
+				}
+				stmt.Fields = append(stmt.Fields, p.identifier().(*ast.Identifier))
+			}
+		case token.DEF:
+			method := p.functionStatement()
+			if method == nil {
+				return nil
+			}
+			stmt.Methods = append(stmt.Methods, method)
+		default:
+			p.peekError(token.RBRACE)
+			return nil
+		}
+		p.nextToken()
+	}
+	stmt.Span = p.span(start)
+	return stmt
+}
+
 // forStatement:
 //     'for' identifier 'in' identifier '{' blockStatement '}'
 //     'for' expression '{' blockStatement '}'
 func (p *Parser) forStatement() *ast.ForStatement {
 	stmt := &ast.ForStatement{}
+	start := p.currentPos
 	p.nextToken()
 	if p.currentTokenIs(token.IDVAL) && p.peekTokenIs(token.IN) {
 		stmt.Token = token.IN
@@ -331,7 +564,10 @@ func (p *Parser) forStatement() *ast.ForStatement {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
+	p.loopDepth++
 	stmt.LoopBody = p.blockStatement()
+	p.loopDepth--
+	stmt.Span = p.span(start)
 	return stmt
 }
 
@@ -343,6 +579,7 @@ func (p *Parser) blockStatement() *ast.BlockStatement {
 		p.nextToken()
 	}
 	block := &ast.BlockStatement{Token: p.currentToken}
+	start := p.currentPos
 	block.Statements = []ast.Statement{}
 	p.nextToken()
 	for !p.currentTokenIs(token.RBRACE) && !p.currentTokenIs(token.EOF) {
@@ -355,6 +592,7 @@ func (p *Parser) blockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	block.Span = p.span(start)
 	return block
 }
 
@@ -398,6 +636,7 @@ func (p *Parser) expression(precedence uint) ast.Expression {
 //     'func' '(' functionParameters ')' '->' expression
 func (p *Parser) lambdaFunction() ast.Expression {
 	lambda := &ast.LambdaFunction{Token: p.currentToken}
+	start := p.currentPos
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
@@ -407,12 +646,16 @@ func (p *Parser) lambdaFunction() ast.Expression {
 	}
 	p.nextToken()
 	lambda.Expr = p.expression(token.LOWEST)
+	lambda.Span = p.span(start)
 	return lambda
 }
 
 // functionParameters:
-//     identifer ',' functionParameters
+//     parameter ',' functionParameters
+//     parameter
+// parameter:
 //     identifier
+//     identifier ':' identifier
 func (p *Parser) functionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 	if p.peekTokenIs(token.RPAREN) {
@@ -421,11 +664,17 @@ func (p *Parser) functionParameters() []*ast.Identifier {
 	}
 	p.nextToken()
 	idVal := p.identifier().(*ast.Identifier)
+	if !p.typeAnnotation(idVal) {
+		return nil
+	}
 	identifiers = append(identifiers, idVal)
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
 		idVal := p.identifier().(*ast.Identifier)
+		if !p.typeAnnotation(idVal) {
+			return nil
+		}
 		identifiers = append(identifiers, idVal)
 	}
 	if !p.expectPeek(token.RPAREN) {
@@ -434,11 +683,27 @@ func (p *Parser) functionParameters() []*ast.Identifier {
 	return identifiers
 }
 
+// typeAnnotation : parse the optional ':' identifier following the name of a
+// parameter or let, returning false if it is malformed.
+func (p *Parser) typeAnnotation(id *ast.Identifier) bool {
+	if !p.peekTokenIs(token.COLON) {
+		return true
+	}
+	p.nextToken()
+	if !p.expectPeek(token.IDVAL) {
+		return false
+	}
+	id.Type = p.identifier().(*ast.Identifier)
+	return true
+}
+
 // callExpression:
 //     identifier '(' expressionList ')'
 func (p *Parser) callExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.currentToken, Function: function}
+	start := p.startOf(function)
 	exp.Arguments = p.expressionList(token.RPAREN)
+	exp.Span = p.span(start)
 	return exp
 }
 
@@ -483,6 +748,7 @@ func (p *Parser) ifElseExpression(inital ast.Expression) ast.Expression {
 		Initial: inital,
 		Token:   p.currentToken,
 	}
+	start := p.startOf(inital)
 	p.nextToken()
 	expr.Condition = p.expression(token.LOWEST)
 	if !p.expectPeek(token.ELSE) {
@@ -493,6 +759,7 @@ func (p *Parser) ifElseExpression(inital ast.Expression) ast.Expression {
 		p.nextToken()
 	}
 	expr.Alternative = p.expression(token.LOWEST)
+	expr.Span = p.span(start)
 	return expr
 }
 
@@ -503,8 +770,10 @@ func (p *Parser) prefixExpression() ast.Expression {
 		Token:    p.currentToken,
 		Operator: p.currentLiteral,
 	}
+	start := p.currentPos
 	p.nextToken()
 	expr.Right = p.expression(token.PREFIX)
+	expr.Span = p.span(start)
 	return expr
 }
 
@@ -516,9 +785,11 @@ func (p *Parser) infixExpression(left ast.Expression) ast.Expression {
 		Operator: p.currentLiteral,
 		Left:     left,
 	}
+	start := p.startOf(left)
 	precedence := p.currentToken.Precedence()
 	p.nextToken()
 	expr.Right = p.expression(precedence)
+	expr.Span = p.span(start)
 	return expr
 }
 
@@ -542,6 +813,7 @@ func (p *Parser) groupedExpression() ast.Expression {
 //     identifier '[' expression ']'
 func (p *Parser) indexExpression(item ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.currentToken, Left: item}
+	start := p.startOf(item)
 	p.nextToken()
 	exp.Index = p.expression(token.LOWEST)
 	if p.peekTokenIs(token.COLON) {
@@ -550,6 +822,19 @@ func (p *Parser) indexExpression(item ast.Expression) ast.Expression {
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.Span = p.span(start)
+	return exp
+}
+
+// attributeExpression
+// 		expression '.' identifier
+func (p *Parser) attributeExpression(left ast.Expression) ast.Expression {
+	exp := &ast.AttributeExpression{Token: p.currentToken, Left: left}
+	if !p.expectPeek(token.IDVAL) {
+		return nil
+	}
+	exp.Name = p.identifier().(*ast.Identifier)
+	exp.Span = p.span(p.startOf(left))
 	return exp
 }
 
@@ -569,6 +854,7 @@ func (p *Parser) sliceExpression(left, start ast.Expression) ast.Expression {
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.Span = p.span(p.startOf(left))
 	return exp
 }
 
@@ -582,6 +868,7 @@ func (p *Parser) dictLiteral() ast.Expression {
 		Token: p.currentToken,
 		Items: make(map[ast.Expression]ast.Expression),
 	}
+	start := p.currentPos
 	if p.peekTokenIs(token.NEWLINE) {
 		p.nextToken()
 	}
@@ -605,28 +892,47 @@ func (p *Parser) dictLiteral() ast.Expression {
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	dict.Span = p.span(start)
 	return dict
 }
 
 // arrayLiteral: '[' expressionList ']'
 func (p *Parser) arrayLiteral() ast.Expression {
-	return &ast.ArrayLiteral{
-		Token:    p.currentToken,
-		Elements: p.expressionList(token.RBRACKET),
-	}
+	array := &ast.ArrayLiteral{Token: p.currentToken}
+	start := p.currentPos
+	array.Elements = p.expressionList(token.RBRACKET)
+	array.Span = p.span(start)
+	return array
 }
 
 // stringLiteral: "[^"]*"
 func (p *Parser) stringLiteral() ast.Expression {
 	return &ast.StringLiteral{
+		Span:  p.span(p.currentPos),
 		Token: p.currentToken,
 		Value: p.currentLiteral,
 	}
 }
 
+// charLiteral: '[^']'
+func (p *Parser) charLiteral() ast.Expression {
+	value, size := utf8.DecodeRuneInString(p.currentLiteral)
+	if size == 0 || size != len(p.currentLiteral) {
+		p.charError()
+		return nil
+	}
+	return &ast.CharLiteral{
+		Span:    p.span(p.currentPos),
+		Token:   p.currentToken,
+		Literal: p.currentLiteral,
+		Value:   value,
+	}
+}
+
 // identifier: [A-Za-z_][A-Za-z_0-9]*
 func (p *Parser) identifier() ast.Expression {
 	return &ast.Identifier{
+		Span:  p.span(p.currentPos),
 		Token: p.currentToken,
 		Value: p.currentLiteral,
 	}
@@ -634,9 +940,17 @@ func (p *Parser) identifier() ast.Expression {
 
 // integer: base10Int | hexInt
 func (p *Parser) integerLiteral() ast.Expression {
-	lit := &ast.IntegerLiteral{Token: p.currentToken, Literal: p.currentLiteral}
+	lit := &ast.IntegerLiteral{
+		Span:    p.span(p.currentPos),
+		Token:   p.currentToken,
+		Literal: p.currentLiteral,
+	}
 	value, err := strconv.ParseInt(p.currentLiteral, 0, 64)
 	if err != nil {
+		if b, ok := new(big.Int).SetString(p.currentLiteral, 0); ok {
+			lit.Big = b
+			return lit
+		}
 		p.genericError("integer", err)
 		return nil
 	}
@@ -645,7 +959,11 @@ func (p *Parser) integerLiteral() ast.Expression {
 }
 
 func (p *Parser) floatLiteral() ast.Expression {
-	lit := &ast.FloatLiteral{Token: p.currentToken, Literal: p.currentLiteral}
+	lit := &ast.FloatLiteral{
+		Span:    p.span(p.currentPos),
+		Token:   p.currentToken,
+		Literal: p.currentLiteral,
+	}
 	value, err := strconv.ParseFloat(p.currentLiteral, 64)
 	if err != nil {
 		p.genericError("float", err)
@@ -658,6 +976,7 @@ func (p *Parser) floatLiteral() ast.Expression {
 // boolean: true | false
 func (p *Parser) booleanLiteral() ast.Expression {
 	return &ast.BooleanLiteral{
+		Span:  p.span(p.currentPos),
 		Token: p.currentToken,
 		Value: p.currentTokenIs(token.TRUE),
 	}
@@ -665,11 +984,35 @@ func (p *Parser) booleanLiteral() ast.Expression {
 
 // importStatement
 //     'import' identifier
+//     'import' identifier 'as' identifier
+//     'from' identifier 'import' identifier ',' ...
 func (p *Parser) importStatement() *ast.ImportStatement {
 	is := &ast.ImportStatement{Token: p.currentToken}
+	start := p.currentPos
 	if !p.expectPeek(token.IDVAL) {
 		return nil
 	}
 	is.Value = p.identifier().(*ast.Identifier).Value
+	switch {
+	case is.Token == token.FROM:
+		if !p.expectPeek(token.IMPORT) || !p.expectPeek(token.IDVAL) {
+			return nil
+		}
+		is.Names = append(is.Names, p.identifier().(*ast.Identifier))
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.IDVAL) {
+				return nil
+			}
+			is.Names = append(is.Names, p.identifier().(*ast.Identifier))
+		}
+	case p.peekTokenIs(token.AS):
+		p.nextToken()
+		if !p.expectPeek(token.IDVAL) {
+			return nil
+		}
+		is.Alias = p.identifier().(*ast.Identifier)
+	}
+	is.Span = p.span(start)
 	return is
 }
@@ -434,3 +434,198 @@ func checkParserErrors(t *testing.T, p *Parser) {
 		t.FailNow()
 	}
 }
+
+func TestNodePositions(t *testing.T) {
+	input := `let x = 10
+def add(a, b) {
+    return a + b
+}
+add(x, [1, 2])[0]`
+	program := parseTestProgram(t, input)
+	testStatementsLen(t, program, 0, 3)
+
+	assign := program.Statements[0].(*ast.AssignmentStatement)
+	fn := program.Statements[1].(*ast.Function)
+	ret := fn.Body.Statements[0].(*ast.ReturnStatement)
+	infix := ret.Value.(*ast.InfixExpression)
+	stmt := program.Statements[2].(*ast.ExpressionStatement)
+	index := stmt.Expression.(*ast.IndexExpression)
+	call := index.Left.(*ast.CallExpression)
+	array := call.Arguments[1].(*ast.ArrayLiteral)
+
+	tests := []struct {
+		node       ast.Node
+		start, end [2]int // line, column
+	}{
+		{assign, [2]int{1, 1}, [2]int{1, 11}},
+		{assign.Name, [2]int{1, 5}, [2]int{1, 6}},
+		{assign.Value, [2]int{1, 9}, [2]int{1, 11}},
+		{fn, [2]int{2, 1}, [2]int{4, 2}},
+		{ret, [2]int{3, 5}, [2]int{3, 17}},
+		{infix, [2]int{3, 12}, [2]int{3, 17}},
+		{infix.Right, [2]int{3, 16}, [2]int{3, 17}},
+		{index, [2]int{5, 1}, [2]int{5, 18}},
+		{call, [2]int{5, 1}, [2]int{5, 15}},
+		{array, [2]int{5, 8}, [2]int{5, 14}},
+	}
+	for i, tt := range tests {
+		start, end := tt.node.Pos(), tt.node.End()
+		if start.Line != tt.start[0] || start.Column != tt.start[1] {
+			t.Errorf("test[%d] %T wrong start. want=%v, got=%s", i, tt.node, tt.start, start)
+		}
+		if end.Line != tt.end[0] || end.Column != tt.end[1] {
+			t.Errorf("test[%d] %T wrong end. want=%v, got=%s", i, tt.node, tt.end, end)
+		}
+		if start.Filename() != "<input>" {
+			t.Errorf("test[%d] wrong file name. got=%q", i, start.Filename())
+		}
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		expected string
+	}{
+		{"try { x } catch e { raise e }", "e", "try {\nx\n} catch e {\nraise e\n}"},
+		{"try {\n    x\n} catch {\n    y\n}", "", "try {\nx\n} catch {\ny\n}"},
+	}
+	for i, tt := range tests {
+		program := parseTestProgram(t, tt.input)
+		testStatementsLen(t, program, i, 1)
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("test[%d] not *ast.TryStatement. got=%T", i, program.Statements[0])
+		}
+		if (stmt.Name == nil) != (tt.name == "") || stmt.Name != nil && stmt.Name.Value != tt.name {
+			t.Errorf("test[%d] wrong catch name. want=%q, got=%v", i, tt.name, stmt.Name)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("test[%d] wrong string. want=%q, got=%q", i, tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestLoopControlStatement(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors int
+	}{
+		{"for true { break }", 0},
+		{"for x in y { if x { continue } }", 0},
+		{"break", 1},
+		{"if x { continue }", 1},
+		{"for true { def f() { break } }", 1},
+		{"def f() { for true { yield 1 } }", 0},
+		{"yield 1", 1},
+		{"def f() { let g = def() -> 1 }; yield 2", 1},
+	}
+	for i, tt := range tests {
+		p := New(scanner.Init(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) != tt.errors {
+			t.Errorf("test[%d] %q wrong number of errors. want=%d, got=%d",
+				i, tt.input, tt.errors, len(p.Errors()))
+		}
+	}
+}
+
+func TestNonlocalStatement(t *testing.T) {
+	program := parseTestProgram(t, "def f() { nonlocal a, b; a = b }")
+	fn := program.Statements[0].(*ast.Function)
+	stmt, ok := fn.Body.Statements[0].(*ast.NonlocalStatement)
+	if !ok {
+		t.Fatalf("not *ast.NonlocalStatement. got=%T", fn.Body.Statements[0])
+	}
+	if len(stmt.Names) != 2 || stmt.Names[0].Value != "a" || stmt.Names[1].Value != "b" {
+		t.Errorf("wrong names. got=%v", stmt.Names)
+	}
+	if stmt.String() != "nonlocal a, b" {
+		t.Errorf("wrong string. want=%q, got=%q", "nonlocal a, b", stmt.String())
+	}
+
+	for _, input := range []string{"nonlocal a", "def f() { nonlocal }", "def f() { nonlocal a, }"} {
+		p := New(scanner.Init(input))
+		p.ParseProgram()
+		if len(p.Errors()) != 1 {
+			t.Errorf("%q wrong number of errors. want=1, got=%d", input, len(p.Errors()))
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	program := parseTestProgram(t, "def f(x: Int, y) -> Float { let mut z: Float = x }; let g = def(a: String) -> a")
+	fn := program.Statements[0].(*ast.Function)
+	if fn.Parameters[0].TypeName() != "Int" || fn.Parameters[1].TypeName() != "" {
+		t.Errorf("wrong parameter types. got=%q, %q", fn.Parameters[0].TypeName(), fn.Parameters[1].TypeName())
+	}
+	if fn.ReturnTypeName() != "Float" {
+		t.Errorf("wrong return type. want=Float, got=%q", fn.ReturnTypeName())
+	}
+	let := fn.Body.Statements[0].(*ast.AssignmentStatement)
+	if let.Name.Value != "z" || let.Name.TypeName() != "Float" {
+		t.Errorf("wrong let annotation. got=%s: %q", let.Name.Value, let.Name.TypeName())
+	}
+	lambda := program.Statements[1].(*ast.AssignmentStatement).Value.(*ast.LambdaFunction)
+	if lambda.Parameters[0].TypeName() != "String" {
+		t.Errorf("wrong lambda parameter type. got=%q", lambda.Parameters[0].TypeName())
+	}
+
+	for _, input := range []string{"let x: = 1", "def f(x:) { x }", "def f() -> { 1 }", "def f() -> Int"} {
+		p := New(scanner.Init(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q expected a parse error", input)
+		}
+	}
+}
+
+func TestStructStatement(t *testing.T) {
+	input := `struct Point {
+    x, y
+    z
+    def norm(self) { return self.x }
+}
+p.x.y = 1`
+	program := parseTestProgram(t, input)
+	testStatementsLen(t, program, 0, 2)
+	stmt, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("not *ast.StructStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Name.Value != "Point" || len(stmt.Fields) != 3 || len(stmt.Methods) != 1 {
+		t.Fatalf("wrong struct. got=%q", stmt.String())
+	}
+	for i, name := range []string{"x", "y", "z"} {
+		if stmt.Fields[i].Value != name {
+			t.Errorf("field[%d] wrong. want=%q, got=%q", i, name, stmt.Fields[i].Value)
+		}
+	}
+	assign, ok := program.Statements[1].(*ast.AttributeAssignmentStatement)
+	if !ok {
+		t.Fatalf("not *ast.AttributeAssignmentStatement. got=%T", program.Statements[1])
+	}
+	if assign.String() != "p.x.y = 1" {
+		t.Errorf("wrong string. got=%q", assign.String())
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []string{
+		"import std",
+		"import std as s",
+		"from std import map, filter",
+	}
+	for i, input := range tests {
+		program := parseTestProgram(t, input)
+		testStatementsLen(t, program, i, 1)
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("test[%d] not *ast.ImportStatement. got=%T", i, program.Statements[0])
+		}
+		if stmt.String() != input {
+			t.Errorf("test[%d] wrong string. want=%q, got=%q", i, input, stmt.String())
+		}
+	}
+}
@@ -41,7 +41,7 @@ func Start(in io.Reader, out io.Writer) {
 		if line == QUIT {
 			return
 		}
-		l := scanner.Init(line + "\n")
+		l := scanner.InitFile("<stdin>", line+"\n")
 		p := parser.New(l)
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
@@ -49,6 +49,10 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 		evaluated := eval.Eval(program, env)
+		if err, ok := evaluated.(*object.Error); ok {
+			io.WriteString(out, err.Traceback())
+			continue
+		}
 		if evaluated != nil && evaluated != object.NONE {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
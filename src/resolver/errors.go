@@ -0,0 +1,59 @@
+package resolver
+
+import (
+	"dito/src/token"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Error : a problem found in a program before it is run. Warnings point out
+// code which is likely a mistake but does not stop the program running.
+type Error struct {
+	Message string
+	Pos     token.Pos
+	Warning bool
+}
+
+func (e *Error) String() string {
+	message := e.Message
+	if e.Warning {
+		message = "Warning: " + message
+	}
+	line := strings.TrimRight(e.Pos.Source.Line(e.Pos.Line), "\n\t\r ")
+	return fmt.Sprintf("Traceback line %d column %d:\n%s%s\n",
+		e.Pos.Line, e.Pos.Column,
+		token.FormatTrace(line, e.Pos.Column-1, "^ Is your problem here?"),
+		message)
+}
+
+// PrintErrors : output all errors found by the resolver, under a header
+// saying whether any stop the program running.
+func PrintErrors(out io.Writer, errors []*Error) {
+	if HasErrors(errors) {
+		io.WriteString(out, "RESOLVE ERROR:\n")
+	} else {
+		io.WriteString(out, "RESOLVE WARNING:\n")
+	}
+	for _, err := range errors {
+		io.WriteString(out, err.String())
+	}
+}
+
+// HasErrors : report whether any of errors is not just a warning.
+func HasErrors(errors []*Error) bool {
+	for _, err := range errors {
+		if !err.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *resolver) error(pos token.Pos, format string, a ...interface{}) {
+	r.errors = append(r.errors, &Error{Message: fmt.Sprintf(format, a...), Pos: pos})
+}
+
+func (r *resolver) warning(pos token.Pos, format string, a ...interface{}) {
+	r.errors = append(r.errors, &Error{Message: fmt.Sprintf(format, a...), Pos: pos, Warning: true})
+}
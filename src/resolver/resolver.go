@@ -0,0 +1,410 @@
+/*
+Package resolver checks a parsed program before it runs. Following the
+scoping rules of the evaluator it reports identifiers which are never defined,
+assignments to immutable variables or to variables of enclosing functions
+which are not declared nonlocal, calls to known functions with the wrong
+number of arguments, and variables which are never used. Values whose type
+is known without running the program are checked against the type
+annotations they are passed to, assigned to or returned from.
+*/
+package resolver
+
+import (
+	"dito/src/ast"
+	"dito/src/eval"
+	"dito/src/object"
+	"dito/src/token"
+	"sort"
+	"strings"
+)
+
+type scopeKind int
+
+const (
+	moduleScope scopeKind = iota
+	functionScope
+	blockScope
+)
+
+// binding : a name declared in a scope.
+type binding struct {
+	name    string
+	pos     token.Pos
+	mutable bool
+	arity   int  // the number of args it can be called with, -1 when unknown.
+	local   bool // a let of a function or block, which should be used.
+	used    bool
+
+	typ     string   // the type of its values when known, or empty.
+	params  []string // the annotated types of the parameters of a function.
+	returns string   // the type of the value returned by calling it.
+}
+
+// scope : the compile time counterpart of object.Environment.
+type scope struct {
+	kind      scopeKind
+	outer     *scope
+	names     map[string]*binding
+	nonlocals map[string]bool // names declared nonlocal in a function.
+}
+
+func newScope(kind scopeKind, outer *scope) *scope {
+	return &scope{kind: kind, outer: outer, names: make(map[string]*binding)}
+}
+
+// lookup : find the binding of a name looking outwards through the enclosing
+// scopes, also returning the scope it is in.
+func (s *scope) lookup(name string) (*binding, *scope) {
+	for ; s != nil; s = s.outer {
+		if b, ok := s.names[name]; ok {
+			return b, s
+		}
+	}
+	return nil, nil
+}
+
+// function : the scope of the function or module s is in.
+func (s *scope) function() *scope {
+	for s.kind == blockScope {
+		s = s.outer
+	}
+	return s
+}
+
+// function : a function body, resolved once every name of the scopes around
+// it is known as it can only run once it has been called.
+type function struct {
+	scope   *scope // the scope the function is defined in.
+	name    string
+	params  []*ast.Identifier
+	returns *ast.Identifier // the annotated return type, checked when set.
+	body    ast.Node
+}
+
+// resolver : walks statements in the order they run, declaring names as it
+// goes so that using a name before its declaration is reported.
+type resolver struct {
+	scope     *scope
+	name      string // the name of the function being resolved.
+	returns   string // its return type, checked when not empty.
+	functions []function
+	locals    []*binding
+	errors    []*Error
+	types     bool // report values whose type does not match their annotation.
+}
+
+// Resolve : check a program returning the errors and warnings found, in the
+// order of their positions.
+func Resolve(program *ast.Program) []*Error {
+	return resolveProgram(program, false)
+}
+
+// Check : Resolve a program, also reporting the values whose type is known
+// before it runs and does not match the annotation they are given to.
+func Check(program *ast.Program) []*Error {
+	return resolveProgram(program, true)
+}
+
+func resolveProgram(program *ast.Program, types bool) []*Error {
+	r := &resolver{scope: newScope(moduleScope, nil), types: types}
+	r.statements(program.Statements)
+	for len(r.functions) > 0 {
+		fn := r.functions[0]
+		r.functions = r.functions[1:]
+		r.function(fn)
+	}
+	for _, b := range r.locals {
+		if !b.used && !strings.HasPrefix(b.name, "_") {
+			r.warning(b.pos, "variable '%s' is declared but never used", b.name)
+		}
+	}
+	sort.SliceStable(r.errors, func(i, j int) bool {
+		a, b := r.errors[i].Pos, r.errors[j].Pos
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return r.errors
+}
+
+func (r *resolver) function(fn function) {
+	r.scope = newScope(functionScope, fn.scope)
+	r.name, r.returns = fn.name, r.annotation(fn.returns)
+	for _, p := range fn.params {
+		r.declare(p.Value, p.Pos(), false, -1).typ = r.annotation(p.Type)
+	}
+	switch body := fn.body.(type) {
+	case *ast.BlockStatement:
+		r.statements(body.Statements)
+	case ast.Expression:
+		r.expression(body)
+	}
+}
+
+// later : resolve a function body after the code around it.
+func (r *resolver) later(name string, params []*ast.Identifier, returns *ast.Identifier, body ast.Node) {
+	r.functions = append(r.functions, function{
+		scope: r.scope, name: name, params: params, returns: returns, body: body,
+	})
+}
+
+func (r *resolver) declare(name string, pos token.Pos, mutable bool, arity int) *binding {
+	if _, ok := r.scope.names[name]; ok {
+		r.error(pos, "identifier '%s' is already declared in this scope", name)
+	}
+	b := &binding{name: name, pos: pos, mutable: mutable, arity: arity}
+	r.scope.names[name] = b
+	return b
+}
+
+// block : resolve statements in a scope of their own, declare is called
+// first to add names such as the variable of a loop.
+func (r *resolver) block(stmts []ast.Statement, declare func()) {
+	r.scope = newScope(blockScope, r.scope)
+	if declare != nil {
+		declare()
+	}
+	r.statements(stmts)
+	r.scope = r.scope.outer
+}
+
+func (r *resolver) statements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			r.statement(stmt)
+		}
+	}
+}
+
+func (r *resolver) statement(node ast.Statement) {
+	switch node := node.(type) {
+	case *ast.AssignmentStatement:
+		r.expression(node.Value)
+		mutable := node.Token != token.LET
+		arity := -1
+		lambda, isLambda := node.Value.(*ast.LambdaFunction)
+		if isLambda && !mutable {
+			arity = len(lambda.Parameters)
+		}
+		typ := r.annotation(node.Name.Type)
+		r.checkType(node.Value, typ, object.VarTypeError, node.Name.Value)
+		b := r.declare(node.Name.Value, node.Name.Pos(), mutable, arity)
+		if b.typ = typ; typ == "" && !mutable {
+			b.typ = r.typeOf(node.Value)
+		}
+		if isLambda && !mutable {
+			b.params = object.ParamTypes(lambda.Parameters)
+		}
+		if r.scope.kind != moduleScope {
+			r.locals = append(r.locals, b)
+		}
+	case *ast.ReAssignStatement:
+		r.expression(node.Value)
+		if b := r.assign(node.Name); b != nil && node.Token == token.ASSIGN {
+			r.checkType(node.Value, b.typ, object.VarTypeError, node.Name.Value)
+		}
+	case *ast.IndexAssignmentStatement:
+		r.expression(node.IdxExp)
+		r.expression(node.Value)
+	case *ast.AttributeAssignmentStatement:
+		r.expression(node.AttrExp.Left)
+		r.expression(node.Value)
+	case *ast.StructStatement:
+		b := r.declare(node.Name.Value, node.Name.Pos(), false, len(node.Fields))
+		b.typ, b.returns = object.StructType.String(), node.Name.Value
+		for _, m := range node.Methods {
+			r.later(m.Name.Value, m.Parameters, returnType(m), m.Body)
+		}
+	case *ast.Function:
+		b := r.declare(node.Name.Value, node.Name.Pos(), false, len(node.Parameters))
+		b.typ, b.params, b.returns = object.FunctionType.String(), object.ParamTypes(node.Parameters), node.ReturnTypeName()
+		r.later(node.Name.Value, node.Parameters, returnType(node), node.Body)
+	case *ast.ReturnStatement:
+		r.expression(node.Value)
+		r.checkType(node.Value, r.returns, object.ReturnTypeError, r.name)
+	case *ast.YieldStatement:
+		r.expression(node.Value)
+	case *ast.RaiseStatement:
+		r.expression(node.Value)
+	case *ast.ExpressionStatement:
+		r.expression(node.Expression)
+	case *ast.NonlocalStatement:
+		r.nonlocal(node)
+	case *ast.BlockStatement:
+		r.block(node.Statements, nil)
+	case *ast.IfStatement:
+		r.expression(node.Condition)
+		r.block(node.Consequence.Statements, nil)
+		if node.Alternative != nil {
+			r.block(node.Alternative.Statements, nil)
+		}
+	case *ast.ForStatement:
+		if node.ID == nil {
+			r.expression(node.Condition)
+			r.block(node.LoopBody.Statements, nil)
+			break
+		}
+		r.expression(node.Iter)
+		r.block(node.LoopBody.Statements, func() {
+			r.declare(node.ID.Value, node.ID.Pos(), false, -1)
+		})
+	case *ast.TryStatement:
+		r.block(node.Body.Statements, nil)
+		r.block(node.Handler.Statements, func() {
+			if node.Name != nil {
+				r.declare(node.Name.Value, node.Name.Pos(), false, -1)
+			}
+		})
+	case *ast.ImportStatement:
+		switch {
+		case node.Token == token.FROM:
+			for _, n := range node.Names {
+				r.declare(n.Value, n.Pos(), false, -1)
+			}
+		case node.Alias != nil:
+			r.declare(node.Alias.Value, node.Alias.Pos(), false, -1)
+		default:
+			r.declare(node.Value, node.Pos(), false, -1)
+		}
+	}
+}
+
+func (r *resolver) expression(node ast.Expression) {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		r.use(node)
+	case *ast.LambdaFunction:
+		r.later("<lambda>", node.Parameters, nil, node.Expr)
+	case *ast.CallExpression:
+		r.expression(node.Function)
+		for _, arg := range node.Arguments {
+			r.expression(arg)
+		}
+		r.call(node)
+	case *ast.IndexExpression:
+		r.expression(node.Left)
+		r.expression(node.Index)
+	case *ast.AttributeExpression:
+		r.expression(node.Left)
+	case *ast.SliceExpression:
+		r.expression(node.Left)
+		r.expression(node.S)
+		r.expression(node.E)
+	case *ast.PrefixExpression:
+		r.expression(node.Right)
+	case *ast.InfixExpression:
+		r.expression(node.Left)
+		r.expression(node.Right)
+	case *ast.IfElseExpression:
+		r.expression(node.Initial)
+		r.expression(node.Condition)
+		r.expression(node.Alternative)
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			r.expression(el)
+		}
+	case *ast.DictLiteral:
+		for key, val := range node.Items {
+			r.expression(key)
+			r.expression(val)
+		}
+	}
+}
+
+func (r *resolver) use(id *ast.Identifier) {
+	if b, _ := r.scope.lookup(id.Value); b != nil {
+		b.used = true
+		return
+	}
+	if _, ok := eval.Builtins[id.Value]; ok {
+		return
+	}
+	if _, ok := object.SystemVars[id.Value]; ok {
+		return
+	}
+	r.error(id.Pos(), "Identifier not found: '%s'", id.Value)
+}
+
+// assign : check the variable assigned to by a ReAssignStatement may be,
+// returning its binding when it is.
+func (r *resolver) assign(id *ast.Identifier) *binding {
+	b, s := r.scope.lookup(id.Value)
+	if b == nil {
+		if _, ok := object.SystemVars[id.Value]; ok {
+			r.error(id.Pos(), "identifier '%s' has a immutable value", id.Value)
+		} else {
+			r.error(id.Pos(), "identifier not found: '%s'", id.Value)
+		}
+		return nil
+	}
+	if !b.mutable {
+		r.error(id.Pos(), "identifier '%s' has a immutable value", id.Value)
+		return nil
+	}
+	fn := r.scope.function()
+	for inner := r.scope; inner != fn.outer; inner = inner.outer {
+		if inner == s {
+			return b
+		}
+	}
+	if !fn.nonlocals[id.Value] {
+		r.error(id.Pos(), "cannot assign to '%s' of an enclosing scope, declare it nonlocal", id.Value)
+		return nil
+	}
+	return b
+}
+
+func (r *resolver) nonlocal(node *ast.NonlocalStatement) {
+	fn := r.scope.function()
+	if fn.kind == moduleScope {
+		// reported by the parser.
+		return
+	}
+	for _, name := range node.Names {
+		if b, _ := fn.outer.lookup(name.Value); b == nil {
+			r.error(name.Pos(), "no binding for nonlocal '%s' found", name.Value)
+			continue
+		}
+		if fn.nonlocals == nil {
+			fn.nonlocals = make(map[string]bool)
+		}
+		fn.nonlocals[name.Value] = true
+	}
+}
+
+// builtinCall : check the number and types of the args given to a builtin.
+func (r *resolver) builtinCall(node *ast.CallExpression, builtin *object.Builtin) {
+	if err := builtin.CheckArgCount(len(node.Arguments)); err != nil {
+		r.error(node.Pos(), "%s", err.Message)
+		return
+	}
+	for i, arg := range node.Arguments {
+		if typ := builtin.ArgType(i); typ != "" {
+			r.checkType(arg, typ, object.ArgTypeError, i+1, builtin.Name)
+		}
+	}
+}
+
+// call : check the number and types of the args given to a function when
+// they are known.
+func (r *resolver) call(node *ast.CallExpression) {
+	id, ok := node.Function.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	want := -1
+	var types []string
+	if b, _ := r.scope.lookup(id.Value); b != nil {
+		want, types = b.arity, b.params
+	} else if builtin, ok := eval.Builtins[id.Value]; ok {
+		r.builtinCall(node, builtin)
+		return
+	}
+	if want >= 0 && len(node.Arguments) != want {
+		r.error(node.Pos(), object.InvalidArgLenError, id.Value, want, len(node.Arguments))
+		return
+	}
+	for i, typ := range types {
+		if i < len(node.Arguments) {
+			r.checkType(node.Arguments[i], typ, object.ArgTypeError, i+1, id.Value)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"dito/src/ast"
+	"dito/src/parser"
+	"dito/src/scanner"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func resolve(t *testing.T, input string) []string {
+	return resolveWith(t, Resolve, input)
+}
+
+func resolveWith(t *testing.T, resolve func(*ast.Program) []*Error, input string) []string {
+	p := parser.New(scanner.Init(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q", input)
+	}
+	var got []string
+	for _, err := range resolve(program) {
+		msg := fmt.Sprintf("%d:%d %s", err.Pos.Line, err.Pos.Column, err.Message)
+		if err.Warning {
+			msg = "warning " + msg
+		}
+		got = append(got, msg)
+	}
+	return got
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"print(y)", []string{"1:7 Identifier not found: 'y'"}},
+		{"print(x)\nlet x = 1", []string{"1:7 Identifier not found: 'x'"}},
+		{"let x = 1\nx = 2", []string{"2:1 identifier 'x' has a immutable value"}},
+		{"y += 1", []string{"1:1 identifier not found: 'y'"}},
+		{"STDOUT = 1", []string{"1:1 identifier 'STDOUT' has a immutable value"}},
+		{"let x = 1\nlet x = 2", []string{"2:5 identifier 'x' is already declared in this scope"}},
+		{"let mut n = 0\ndef f() { n = 1 }", []string{"2:11 cannot assign to 'n' of an enclosing scope, declare it nonlocal"}},
+		{"def f() { nonlocal missing }", []string{"1:20 no binding for nonlocal 'missing' found"}},
+		{"def f(a, b) { a + b }\nf(1)", []string{"2:1 Wrong number of args to function f. Want=2. Got=1."}},
+		{"let g = def(n) -> n\ng()", []string{"2:1 Wrong number of args to function g. Want=1. Got=0."}},
+		{"struct P { x, y }\nP(1)", []string{"2:1 Wrong number of args to function P. Want=2. Got=1."}},
+		{"len(1, 2)", []string{"1:1 Wrong number of args to function len. Want=1. Got=2."}},
+		{"def f() {\n  let mut a = 1\n  a += 1\n}", []string{"warning 2:11 variable 'a' is declared but never used"}},
+		{"def f() { return y }\nprint(z)", []string{
+			"1:18 Identifier not found: 'y'",
+			"2:7 Identifier not found: 'z'",
+		}},
+	}
+	for _, tt := range tests {
+		got := resolve(t, tt.input)
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong errors for %q.\nwant=%q\ngot= %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestResolveTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x: Int = 1.5", []string{"1:14 Value of 'x' not supported. Want=Int. Got=Float."}},
+		{"let mut s: String = \"a\"\ns = [1]", []string{"2:5 Value of 's' not supported. Want=String. Got=Array."}},
+		{"def f(x: Int) { x }\nf(\"a\")", []string{"2:3 Argument 1 to function f not supported. Want=Int. Got=String."}},
+		{"def f() -> Int { return \"a\" }", []string{"1:25 Return value of function f not supported. Want=Int. Got=String."}},
+		{"def f() -> Bool { return 1 < 2 }\nlet x: Int = f()", []string{"2:14 Value of 'x' not supported. Want=Int. Got=Bool."}},
+		{"let n = len([1])\nlet s: String = n", []string{"2:17 Value of 's' not supported. Want=String. Got=Int."}},
+		{"open(1, \"r\")", []string{"1:6 Argument 1 to function open not supported. Want=String. Got=Int."}},
+		{"struct P { x }\nlet p: P = 1", []string{"2:12 Value of 'p' not supported. Want=P. Got=Int."}},
+		{"let x: Number = 1", []string{"1:8 unknown type 'Number'"}},
+		{"def f(x: P) { x }", []string{"1:10 unknown type 'P'"}},
+	}
+	for _, tt := range tests {
+		got := resolveWith(t, Check, tt.input)
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong errors for %q.\nwant=%q\ngot= %q", tt.input, tt.expected, got)
+		}
+		// only check reports values of the wrong type, running finds them.
+		if got := resolve(t, tt.input); len(got) != 0 && !strings.Contains(got[0], "unknown type") {
+			t.Errorf("type errors reported by Resolve for %q: %q", tt.input, got)
+		}
+	}
+}
+
+func TestResolveValid(t *testing.T) {
+	tests := []string{
+		// functions may use names declared after them, before they are called.
+		"def f() { g() }\ndef g() { 1 }\nf()",
+		"def f() {\n  let mut n = 0\n  def inc() {\n    nonlocal n\n    n += 1\n  }\n  inc()\n  return n\n}",
+		"let mut total = 0\nfor i in range(3) { total += i }\nprint(total)",
+		"try { raise 1 } catch err { print(err) }",
+		"struct P { x\n  def get(self) { self.x }\n}\nprint(P(1).get())",
+		"def f() { let _ignored = 1 }",
+		"let a = [1]\na[0] = 2\nlet add = def(x, y) -> x + y\nprint(add(1, 2))",
+		"struct P { x }\ndef f(p: P, n: Numeric) -> Instance { return p }\nlet q: P = f(P(1), 2.5)",
+		"def f(x) -> Int { return x }\nlet mut y: Any = f(1)\ny = \"a\"",
+		"def g() -> Iterator {\n  yield 1\n  return 2\n}",
+	}
+	for _, input := range tests {
+		if got := resolve(t, input); len(got) != 0 {
+			t.Errorf("unexpected errors for %q: %q", input, got)
+		}
+	}
+}
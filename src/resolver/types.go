@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"dito/src/ast"
+	"dito/src/eval"
+	"dito/src/object"
+)
+
+// annotation : the type named by an annotation, which must be known to the
+// runtime or be a struct. Empty when there is no annotation.
+func (r *resolver) annotation(typ *ast.Identifier) string {
+	if typ == nil {
+		return ""
+	}
+	if !object.KnownType(typ.Value) {
+		b, _ := r.scope.lookup(typ.Value)
+		if b == nil || b.typ != object.StructType.String() {
+			r.error(typ.Pos(), "unknown type '%s'", typ.Value)
+			return ""
+		}
+	}
+	return typ.Value
+}
+
+// returnType : the annotation a function checks its return values against.
+// The values returned by a generator end it rather than being its result.
+func returnType(fn *ast.Function) *ast.Identifier {
+	if fn.Generator {
+		return nil
+	}
+	return fn.ReturnType
+}
+
+// typeOf : the type of the value of an expression when it is known without
+// running the program, otherwise empty.
+func (r *resolver) typeOf(node ast.Expression) string {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		return object.IntType.String()
+	case *ast.FloatLiteral:
+		return object.FloatType.String()
+	case *ast.StringLiteral:
+		return object.StringType.String()
+	case *ast.CharLiteral:
+		return object.CharType.String()
+	case *ast.BooleanLiteral:
+		return object.BoolType.String()
+	case *ast.ArrayLiteral:
+		return object.ArrayType.String()
+	case *ast.DictLiteral:
+		return object.DictType.String()
+	case *ast.LambdaFunction:
+		return object.LambdaType.String()
+	case *ast.Identifier:
+		if b, _ := r.scope.lookup(node.Value); b != nil {
+			return b.typ
+		}
+	case *ast.CallExpression:
+		id, ok := node.Function.(*ast.Identifier)
+		if !ok {
+			break
+		}
+		if b, _ := r.scope.lookup(id.Value); b != nil {
+			return b.returns
+		}
+		if builtin, ok := eval.Builtins[id.Value]; ok {
+			return builtin.ReturnT
+		}
+	case *ast.PrefixExpression:
+		if node.Operator == "not" {
+			return object.BoolType.String()
+		}
+	case *ast.InfixExpression:
+		switch node.Operator {
+		case "==", "!=", "<", ">", "<=", ">=", "in":
+			return object.BoolType.String()
+		}
+	}
+	return ""
+}
+
+// compatible : report whether a value of the type got may have the type
+// want. Only types which are certain to differ are incompatible.
+func compatible(want, got string) bool {
+	if want == "" || got == "" || want == got {
+		return true
+	}
+	_, wantFlag := object.ParseTypeFlag(want)
+	_, gotFlag := object.ParseTypeFlag(got)
+	if !wantFlag && object.KnownType(want) || !gotFlag && object.KnownType(got) {
+		// Any, Atom and the like, which hold values of several types.
+		return true
+	}
+	// the Instances of a struct have its name as their type.
+	instance := object.InstanceType.String()
+	return want == instance && !gotFlag || got == instance && !wantFlag
+}
+
+// checkType : report an error when the type of node is not compatible with
+// want, the message is formatted with args followed by want and the type.
+func (r *resolver) checkType(node ast.Expression, want, message string, args ...interface{}) {
+	if node == nil || !r.types {
+		return
+	}
+	if got := r.typeOf(node); !compatible(want, got) {
+		r.error(node.Pos(), message, append(args, want, got)...)
+	}
+}
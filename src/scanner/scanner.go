@@ -16,6 +16,7 @@ import (
 
 // Scanner implements the methods needed to scan a program.
 type Scanner struct {
+	source  *token.Source
 	input   string // Fixed state from new.
 	char    byte   // current char under examination
 	pos     int    // current char in input
@@ -24,20 +25,56 @@ type Scanner struct {
 	lineno  int    // current line under examination
 	column  int    // current column position.
 	newline bool   // if or not to collect newline tokens.
+	tokLine int    // line the last returned token started on.
+	tokCol  int    // column the last returned token started on.
+	endLine int    // line the last returned token ended on.
+	endCol  int    // column immediately after the last returned token.
 }
 
 // Init return an intialized lexical scanner. Values not
 // initalized are set to go's default type values :
 // 0 for all integers, false for bool.
 func Init(input string) *Scanner {
-	input += " " // add a buffer space at the end.
-	s := &Scanner{input: input}
+	return InitFile("<input>", input)
+}
+
+// InitFile return an initialized lexical scanner for the contents of a
+// named file. The name is recorded in the position of every token.
+func InitFile(filename, input string) *Scanner {
+	s := &Scanner{source: token.NewSource(filename, input)}
+	s.input = input + " " // add a buffer space at the end.
 	s.advance()
 	return s
 }
 
+// Pos returns the position of the start of the last token returned by
+// NextToken.
+func (s *Scanner) Pos() token.Pos {
+	return token.Pos{Source: s.source, Line: s.tokLine + 1, Column: s.tokCol + 1}
+}
+
+// End returns the position immediately after the last token returned by
+// NextToken.
+func (s *Scanner) End() token.Pos {
+	return token.Pos{Source: s.source, Line: s.endLine + 1, Column: s.endCol + 1}
+}
+
+func (s *Scanner) markToken() {
+	s.tokLine = s.lineno
+	s.tokCol = s.pos - s.linePos
+}
+
+func (s *Scanner) markEnd(tok token.Token) {
+	if tok == token.NEWLINE || tok == token.EOF {
+		s.endLine, s.endCol = s.tokLine, s.tokCol+1
+		return
+	}
+	s.endLine, s.endCol = s.lineno, s.pos-s.linePos
+}
+
 // NextToken : Returns the next token encountered by the lexical scanner.
 func (s *Scanner) NextToken() (tok token.Token, literal string, line int) {
+	defer func() { s.markEnd(tok) }()
 
 	// ------------------------------------------------------------------------
 	// * * * TODO * * *
@@ -46,6 +83,7 @@ func (s *Scanner) NextToken() (tok token.Token, literal string, line int) {
 	// trailing comments.
 
 	if s.newline && s.char == '\n' {
+		s.markToken()
 		s.newline = false
 		s.advanceLine()
 		return token.NEWLINE, token.NEWLINE.String(), s.lineno - 1
@@ -54,6 +92,7 @@ func (s *Scanner) NextToken() (tok token.Token, literal string, line int) {
 	s.newline = true
 
 	s.skipWhitespace()
+	s.markToken()
 
 	// ------------------------------------------------------------------------
 
@@ -106,11 +145,13 @@ func (s *Scanner) NextToken() (tok token.Token, literal string, line int) {
 		tok = token.COLON
 	case '"':
 		return s.readString()
+	case '\'':
+		return s.readChar()
 	case '.':
 		if isDigit(s.peek()) {
 			return s.readNumber()
 		}
-		tok = token.ILLEGAL
+		tok = token.PERIOD
 	case 0:
 		// token.EOF represents end of input. the scanners caller should
 		// check for this to find out when to stop iterating.
@@ -183,6 +224,28 @@ func (s *Scanner) readString() (token.Token, string, int) {
 	return token.STRING, b.String(), s.lineno
 }
 
+// readChar reads until it sees a single quote or 0 (EOF). The parser checks
+// the literal is a single character.
+func (s *Scanner) readChar() (token.Token, string, int) {
+	var b bytes.Buffer
+	for {
+		s.advance()
+		if s.char == '\'' {
+			break
+		}
+		if s.char == '\n' || s.char == 0 {
+			return token.UNEXPECTEDEOF, b.String(), s.lineno
+		}
+		if s.char == '\\' {
+			b.WriteByte(s.escapeString())
+			continue
+		}
+		b.WriteByte(s.char)
+	}
+	s.advance()
+	return token.CHAR, b.String(), s.lineno
+}
+
 func (s *Scanner) escapeString() byte {
 	s.advance()
 	var val byte
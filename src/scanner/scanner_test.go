@@ -141,7 +141,7 @@ x /= fun << 1
 		{token.NEWLINE, "NEWLINE", 11, 0},
 
 		// .@ @
-		{token.ILLEGAL, "ILLEGAL", 12, 0},
+		{token.PERIOD, ".", 12, 0},
 		{token.ILLEGAL, "ILLEGAL", 12, 0},
 		{token.ILLEGAL, "ILLEGAL", 12, 0},
 		{token.NEWLINE, "NEWLINE", 12, 0},
@@ -244,3 +244,30 @@ let x = 0xff >= 5000
 		}
 	}
 }
+
+func TestChars(t *testing.T) {
+	tests := []struct {
+		token   token.Token
+		literal string
+	}{
+		{token.CHAR, "a"},
+		{token.CHAR, "é"},
+		{token.CHAR, "\n"},
+		{token.CHAR, "'"},
+		{token.STRING, "'b'"},
+		{token.CHAR, "ab"},
+		{token.UNEXPECTEDEOF, "c "},
+	}
+	scanner := Init(`'a' 'é' '\n' '\'' "'b'" 'ab' 'c`)
+	for i, tt := range tests {
+		tok, literal, _ := scanner.NextToken()
+		if tok != tt.token {
+			t.Fatalf("test[%d] - Invalid Token. expected=%q, got=%q",
+				i, tt.token.String(), tok.String())
+		}
+		if literal != tt.literal {
+			t.Fatalf("test[%d] - Invalid Literal. expected=%q, got=%q",
+				i, tt.literal, literal)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source : a named piece of source text. It is shared by every position
+// scanned from it so tracebacks can show the offending line.
+type Source struct {
+	Name  string
+	lines []string
+}
+
+// NewSource : return a new source, name is usually a file path.
+func NewSource(name, text string) *Source {
+	return &Source{Name: name, lines: strings.Split(text, "\n")}
+}
+
+// Line : return line n of the source, counting from 1.
+func (s *Source) Line(n int) string {
+	if s == nil || n < 1 || n > len(s.lines) {
+		return ""
+	}
+	return s.lines[n-1]
+}
+
+// Pos : a position within a source. Lines and columns count from 1, the zero
+// value is an unknown position.
+type Pos struct {
+	Source *Source
+	Line   int
+	Column int
+}
+
+// IsValid : is the position known.
+func (p Pos) IsValid() bool { return p.Line > 0 }
+
+// Filename : the name of the source the position is in.
+func (p Pos) Filename() string {
+	if p.Source == nil {
+		return ""
+	}
+	return p.Source.Name
+}
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename(), p.Line, p.Column)
+}
+
+// FormatTrace : render a line of source with a note pointing at a zero based
+// column. This is the caret style used by all of dito's error messages.
+func FormatTrace(line string, column int, note string) string {
+	return fmt.Sprintf("%*s%s\n%*s%s\n", 4, " ", line, 4+column, " ", note)
+}
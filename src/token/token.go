@@ -18,6 +18,7 @@ const (
 	INT    // Generic Integers.
 	FLOAT  // Generic Floats.
 	STRING // Strings starting and ending with double quotes.
+	CHAR   // A single character between single quotes.
 	BOOL   // Generic bool
 	endLiteral
 
@@ -56,9 +57,10 @@ const (
 	LBRACKET // [
 	RBRACKET // ]
 
-	SEMI  // ;
-	COLON // :
-	COMMA // ,
+	SEMI   // ;
+	COLON  // :
+	COMMA  // ,
+	PERIOD // .
 	endOperator
 
 	beginAssignementOp
@@ -78,20 +80,30 @@ const (
 	HASH // # start comments
 
 	beginKeyword
-	TRUE   // true
-	FALSE  // false
-	IF     // if
-	ELSE   // else
-	FOR    // for
-	IN     // in
-	NOT    // not
-	DEF    // func
-	AND    // and
-	OR     // or
-	LET    // let
-	MUT    // mut
-	RETURN // return
-	IMPORT // import
+	TRUE     // true
+	FALSE    // false
+	IF       // if
+	ELSE     // else
+	FOR      // for
+	IN       // in
+	NOT      // not
+	DEF      // func
+	AND      // and
+	OR       // or
+	LET      // let
+	MUT      // mut
+	RETURN   // return
+	IMPORT   // import
+	TRY      // try
+	CATCH    // catch
+	RAISE    // raise
+	BREAK    // break
+	CONTINUE // continue
+	STRUCT   // struct
+	FROM     // from
+	AS       // as
+	YIELD    // yield
+	NONLOCAL // nonlocal
 	endKeyword
 )
 
@@ -107,6 +119,7 @@ var tokensLiterals = [...]string{
 	INT:    "Int",
 	FLOAT:  "Float",
 	STRING: "String",
+	CHAR:   "Char",
 	BOOL:   "Bool",
 
 	SUB:  "-",
@@ -151,9 +164,10 @@ var tokensLiterals = [...]string{
 	LBRACKET: "[",
 	RBRACKET: "]",
 
-	SEMI:  ";",
-	COLON: ":",
-	COMMA: ",",
+	SEMI:   ";",
+	COLON:  ":",
+	COMMA:  ",",
+	PERIOD: ".",
 
 	QUOTE: "\"",
 
@@ -172,8 +186,18 @@ var tokensLiterals = [...]string{
 	LET: "let",
 	MUT: "mut",
 
-	IMPORT: "import",
-	RETURN: "return",
+	IMPORT:   "import",
+	RETURN:   "return",
+	TRY:      "try",
+	CATCH:    "catch",
+	RAISE:    "raise",
+	BREAK:    "break",
+	CONTINUE: "continue",
+	STRUCT:   "struct",
+	FROM:     "from",
+	AS:       "as",
+	YIELD:    "yield",
+	NONLOCAL: "nonlocal",
 }
 
 func (t Token) String() string {
@@ -240,7 +264,7 @@ func (t Token) Precedence() uint {
 		return TERM
 	case POW:
 		return EXPONENT
-	case LPAREN:
+	case LPAREN, PERIOD:
 		return CALL
 	case LBRACKET:
 		return HIGHEST
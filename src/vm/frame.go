@@ -0,0 +1,34 @@
+package vm
+
+import (
+	"dito/src/compiler"
+	"dito/src/object"
+)
+
+// Frame : the execution state of a single function call. Locals of the call
+// are stored on the stack starting at basePointer.
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int
+	gen         *generator         // set when the frame is running a generator.
+	tail        *object.TraceFrame // the last tail call made in the frame.
+}
+
+// NewFrame : return a frame ready to execute the closure from its start.
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+// Instructions : the bytecode the frame is executing.
+func (f *Frame) Instructions() compiler.Instructions {
+	return f.cl.Fn.Instructions
+}
+
+// name : the name of the function the frame is executing in tracebacks.
+func (f *Frame) name() string {
+	if f.cl.Fn.IsLambda {
+		return "<lambda>"
+	}
+	return f.cl.Fn.Name
+}
@@ -0,0 +1,123 @@
+package vm
+
+import "dito/src/object"
+
+// generator : the Iterator returned by calling a compiled generator function.
+// Between items its frame, the part of the stack above the frames base
+// pointer and the handlers of its try statements are saved here, and put
+// back on top of the vm to run it to its next yield.
+type generator struct {
+	vm       *VM
+	frame    *Frame
+	stack    []object.Object
+	handlers []handler // relative to the frame.
+	item     object.Object
+	yielded  bool
+	done     bool
+	running  bool
+}
+
+// newGenerator : return a generator which calls cl with args when it is
+// first resumed.
+func (vm *VM) newGenerator(cl *object.Closure, args []object.Object) *generator {
+	stack := make([]object.Object, cl.Fn.NumLocals)
+	copy(stack, args)
+	frame := NewFrame(cl, 0)
+	g := &generator{vm: vm, frame: frame, stack: stack}
+	frame.gen = g
+	return g
+}
+
+func (g *generator) Type() object.TypeFlag { return object.IteratorType }
+func (g *generator) Inspect() string       { return "<generator " + g.frame.cl.Fn.Name + ">" }
+func (g *generator) ConvertType(which object.TypeFlag) object.Object {
+	if which == object.ArrayType {
+		return object.Collect(g.vm.env.Runtime(), g)
+	}
+	return object.NewError(object.ConvertTypeError, g.Type(), which)
+}
+
+// Done : run the generator until it yields the next item or returns.
+func (g *generator) Done() bool {
+	if g.item != nil || g.done {
+		return g.item == nil
+	}
+	if g.running {
+		g.item = object.NewError("generator %s is already running", g.frame.cl.Fn.Name)
+		return false
+	}
+	if err := g.vm.resume(g); err != nil {
+		g.item, g.done = err, true
+		return false
+	}
+	return g.item == nil
+}
+
+// Next : return the item yielded, or the Error which ended the generator.
+func (g *generator) Next() object.Object {
+	if g.Done() {
+		return object.NewError("next called on an exhausted Iterator")
+	}
+	item := g.item
+	g.item = nil
+	return item
+}
+
+// resume : run the generator on top of the current frame until it yields or
+// returns. Errors leaving the generator are returned like those of invoke.
+func (vm *VM) resume(g *generator) *object.Error {
+	base, sp, handlers := vm.framesIndex, vm.sp, len(vm.handlers)
+	if vm.framesIndex >= MaxFrames {
+		return object.NewRecursionError()
+	}
+	if sp+1+len(g.stack) >= StackSize {
+		return object.NewRecursionError()
+	}
+	// the callee slot below the base pointer, as left by call.
+	vm.stack[sp] = g.frame.cl
+	g.frame.basePointer = sp + 1
+	vm.sp = g.frame.basePointer + copy(vm.stack[g.frame.basePointer:], g.stack)
+	vm.pushFrame(g.frame)
+	for _, h := range g.handlers {
+		vm.handlers = append(vm.handlers, handler{vm.framesIndex, h.sp + g.frame.basePointer, h.ip})
+	}
+	g.running = true
+	err := vm.run(base)
+	g.running = false
+	if err != nil {
+		vm.framesIndex, vm.sp, vm.handlers = base, sp, vm.handlers[:handlers]
+		return err
+	}
+	if g.yielded {
+		g.yielded = false
+		return nil
+	}
+	// the generator returned, its value is not an item.
+	vm.pop()
+	g.done = true
+	return nil
+}
+
+// yield : save and pop the frame of the current generator, making val its
+// next item.
+func (vm *VM) yield(val object.Object) *object.Error {
+	frame := vm.currentFrame()
+	g := frame.gen
+	if g == nil {
+		return object.NewError("'yield' outside generator")
+	}
+	g.stack = append(g.stack[:0], vm.stack[frame.basePointer:vm.sp]...)
+	n := len(vm.handlers)
+	for n > 0 && vm.handlers[n-1].framesIndex == vm.framesIndex {
+		n--
+	}
+	g.handlers = g.handlers[:0]
+	for _, h := range vm.handlers[n:] {
+		g.handlers = append(g.handlers, handler{0, h.sp - frame.basePointer, h.ip})
+	}
+	vm.handlers = vm.handlers[:n]
+	vm.popFrame()
+	vm.sp = frame.basePointer - 1
+	g.item, g.yielded = val, true
+	return nil
+}
@@ -0,0 +1,93 @@
+package vm
+
+import (
+	"dito/src/compiler"
+	"dito/src/object"
+)
+
+func minus(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Int:
+		return right.Neg()
+	case *object.Float:
+		return object.NewFloat(-right.Value)
+	case *object.Decimal:
+		return right.Neg()
+	case *object.Rational:
+		return right.Neg()
+	}
+	return object.NewError("Unknown operator: -%s", right.Type())
+}
+
+func not(right object.Object) object.Object {
+	switch right {
+	case object.TRUE:
+		return object.FALSE
+	case object.FALSE, object.NONE:
+		return object.TRUE
+	}
+	if i, ok := right.(*object.Int); ok && i.Value == 0 {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+func isTrue(obj object.Object) bool {
+	return obj != object.NONE && obj != object.FALSE
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ErrorType
+}
+
+func getAttr(left object.Object, name string) object.Object {
+	if attr, ok := left.(object.Attributable); ok {
+		return attr.GetAttr(name)
+	}
+	return object.NewError("'%s' has no attribute '%s'", left.Type(), name)
+}
+
+// module : an imported module, its attributes are read from the globals so
+// they reflect later changes made by the modules own code.
+type module struct {
+	info    *compiler.Module
+	globals []object.Object
+}
+
+func (m *module) Type() object.TypeFlag { return object.ModuleType }
+func (m *module) Inspect() string       { return m.info.Inspect() }
+func (m *module) ConvertType(which object.TypeFlag) object.Object {
+	return m.info.ConvertType(which)
+}
+
+func (m *module) GetAttr(name string) object.Object {
+	if idx, ok := m.info.Slots[name]; ok && m.globals[idx] != nil {
+		return m.globals[idx]
+	}
+	return object.NewError(object.ModuleAttrError, m.info.Name, name)
+}
+
+func (m *module) SetAttr(name string, val object.Object) object.Object {
+	return object.NewError("cannot assign to attribute '%s' of module '%s'", name, m.info.Name)
+}
+
+// cell : a variable captured by closures. The frame defining the variable
+// and every closure capturing it hold the same cell, so an assignment made by
+// any of them is seen by the others.
+type cell struct {
+	value object.Object
+}
+
+func (c *cell) Type() object.TypeFlag { return c.value.Type() }
+func (c *cell) Inspect() string       { return c.value.Inspect() }
+func (c *cell) ConvertType(which object.TypeFlag) object.Object {
+	return c.value.ConvertType(which)
+}
+
+// getCell : the cell in a slot, which is nil until its variable is defined.
+func getCell(slot object.Object) (*cell, *object.Error) {
+	if c, ok := slot.(*cell); ok {
+		return c, nil
+	}
+	return nil, object.NewError("Identifier not found: variable used before assignment")
+}
@@ -0,0 +1,703 @@
+/*Package vm implements a stack based virtual machine which executes the
+bytecode produced by the compiler package. It is an alternative backend to
+the tree walking evaluator in the eval package and shares its object model,
+builtins and binary operations.
+*/
+package vm
+
+import (
+	"dito/src/compiler"
+	"dito/src/eval"
+	"dito/src/object"
+)
+
+// Limits of the vm.
+const (
+	StackSize  = 2048
+	GlobalSize = 65536
+	MaxFrames  = 1024
+)
+
+// binaryOp : satisfied by the values of object.BinaryOps.
+type binaryOp interface {
+	EvalBinary(*object.Environment, object.Object, object.Object) object.Object
+}
+
+var binaryOps []binaryOp
+
+func init() {
+	for _, name := range compiler.BinaryOperators {
+		binaryOps = append(binaryOps, object.BinaryOps[name])
+	}
+}
+
+// VM : executes bytecode.
+type VM struct {
+	constants   []object.Object
+	globals     []object.Object
+	globalNames []string
+
+	stack []object.Object
+	sp    int // stack[sp-1] is the top of the stack.
+
+	frames      []*Frame
+	framesIndex int
+
+	handlers []handler
+	imported map[*compiler.Module]bool
+
+	// env : passed to builtins, its runtime calls functions with invoke.
+	env *object.Environment
+
+	lastPopped object.Object
+}
+
+// handler : the state to restore when an error is caught by a try statement.
+type handler struct {
+	framesIndex int
+	sp          int
+	ip          int
+}
+
+// New : return a vm ready to run the bytecode with DefaultRuntime.
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithRuntime(bytecode, object.DefaultRuntime)
+}
+
+// NewWithRuntime : return a vm ready to run the bytecode with the streams,
+// limits and capabilities of rt.
+func NewWithRuntime(bytecode *compiler.Bytecode, rt *object.Runtime) *VM {
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		NumLocals:    bytecode.NumLocals,
+		Positions:    bytecode.Positions,
+	}
+	frames := make([]*Frame, MaxFrames)
+	frames[0] = NewFrame(&object.Closure{Fn: mainFn}, 0)
+	vm := &VM{
+		constants:   bytecode.Constants,
+		globals:     make([]object.Object, GlobalSize),
+		globalNames: bytecode.Globals,
+		stack:       make([]object.Object, StackSize),
+		frames:      frames,
+		framesIndex: 1,
+		sp:          bytecode.NumLocals,
+		imported:    make(map[*compiler.Module]bool),
+	}
+	run := *rt
+	run.Call = vm.invoke
+	vm.env = object.RuntimeEnvironment(&run)
+	return vm
+}
+
+// Run : execute the bytecode returning the value of the last top level
+// statement, or the Error which stopped execution.
+func (vm *VM) Run() object.Object {
+	if err := vm.run(0); err != nil {
+		return err
+	}
+	return vm.lastPopped
+}
+
+// Call : call the global function name with args once Run has returned,
+// returning its value or the Error which stopped it.
+func (vm *VM) Call(name string, args ...object.Object) object.Object {
+	for idx, global := range vm.globalNames {
+		if global == name && vm.globals[idx] != nil {
+			return vm.invoke(vm.globals[idx], args)
+		}
+	}
+	return object.NewError("Identifier not found: '%s'", name)
+}
+
+func (vm *VM) currentFrame() *Frame { return vm.frames[vm.framesIndex-1] }
+
+func (vm *VM) pushFrame(f *Frame) {
+	vm.frames[vm.framesIndex] = f
+	vm.framesIndex++
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+// run : execute instructions until the frame at index stop returns, or the
+// bytecode ends when stop is 0.
+func (vm *VM) run(stop int) *object.Error {
+	var (
+		ip  int
+		ins compiler.Instructions
+		op  compiler.Opcode
+	)
+	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		vm.currentFrame().ip++
+		ip = vm.currentFrame().ip
+		ins = vm.currentFrame().Instructions()
+		op = compiler.Opcode(ins[ip])
+
+		var err *object.Error
+		switch op {
+		case compiler.OpConstant:
+			idx := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.push(vm.constants[idx])
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpNone:
+			err = vm.push(object.NONE)
+		case compiler.OpTrue:
+			err = vm.push(object.TRUE)
+		case compiler.OpFalse:
+			err = vm.push(object.FALSE)
+
+		case compiler.OpBinary:
+			which := int(ins[ip+1])
+			vm.currentFrame().ip++
+			right := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(vm.binary(which, left, right))
+		case compiler.OpMinus:
+			err = vm.pushResult(minus(vm.pop()))
+		case compiler.OpNot:
+			err = vm.push(not(vm.pop()))
+
+		case compiler.OpJump:
+			vm.currentFrame().ip = int(compiler.ReadUint16(ins[ip+1:])) - 1
+		case compiler.OpJumpNotTrue:
+			vm.currentFrame().ip += 2
+			if !isTrue(vm.pop()) {
+				vm.currentFrame().ip = int(compiler.ReadUint16(ins[ip+1:])) - 1
+			}
+		case compiler.OpJumpNotTrueOrPop, compiler.OpJumpTrueOrPop:
+			vm.currentFrame().ip += 2
+			if isTrue(vm.stack[vm.sp-1]) == (op == compiler.OpJumpTrueOrPop) {
+				vm.currentFrame().ip = int(compiler.ReadUint16(ins[ip+1:])) - 1
+			} else {
+				vm.pop()
+			}
+
+		case compiler.OpGetGlobal:
+			idx := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			val := vm.globals[idx]
+			if val == nil {
+				err = object.NewError("Identifier not found: '%s'", vm.globalNames[idx])
+			} else {
+				err = vm.push(val)
+			}
+		case compiler.OpSetGlobal:
+			idx := compiler.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			vm.globals[idx] = vm.pop()
+		case compiler.OpGetLocal:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			err = vm.pushVar(vm.stack[vm.currentFrame().basePointer+idx])
+		case compiler.OpSetLocal:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			vm.stack[vm.currentFrame().basePointer+idx] = vm.pop()
+		case compiler.OpGetFree:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			err = vm.pushVar(vm.currentFrame().cl.Free[idx])
+
+		case compiler.OpNewCell:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			vm.stack[vm.currentFrame().basePointer+idx] = &cell{value: vm.pop()}
+		case compiler.OpGetCell, compiler.OpGetFreeCell:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			var c *cell
+			if op == compiler.OpGetCell {
+				c, err = getCell(vm.stack[vm.currentFrame().basePointer+idx])
+			} else {
+				c, err = getCell(vm.currentFrame().cl.Free[idx])
+			}
+			if err == nil {
+				err = vm.push(c.value)
+			}
+		case compiler.OpSetCell, compiler.OpSetFreeCell:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			var c *cell
+			if op == compiler.OpSetCell {
+				c, err = getCell(vm.stack[vm.currentFrame().basePointer+idx])
+			} else {
+				c, err = getCell(vm.currentFrame().cl.Free[idx])
+			}
+			if val := vm.pop(); err == nil {
+				c.value = val
+			}
+		case compiler.OpCheckType:
+			typ := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*object.String)
+			name := vm.constants[compiler.ReadUint16(ins[ip+3:])].(*object.String)
+			vm.currentFrame().ip += 4
+			err = object.CheckVarType(name.Value, typ.Value, vm.stack[vm.sp-1])
+
+		case compiler.OpArray:
+			n := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			elements := make([]object.Object, n)
+			copy(elements, vm.stack[vm.sp-n:vm.sp])
+			vm.sp -= n
+			err = vm.push(object.NewArray(elements, -1))
+		case compiler.OpDict:
+			n := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			err = vm.pushResult(vm.buildDict(n))
+		case compiler.OpIndex:
+			key := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(object.GetItem(left, key))
+		case compiler.OpSlice:
+			end := vm.pop()
+			start := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(object.Slice(left, start, end))
+		case compiler.OpSetIndex:
+			which := int(ins[ip+1])
+			vm.currentFrame().ip++
+			val := vm.pop()
+			key := vm.pop()
+			left := vm.pop()
+			err = vm.setIndex(which, left, key, val)
+		case compiler.OpGetAttr:
+			name := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*object.String)
+			vm.currentFrame().ip += 2
+			err = vm.pushResult(getAttr(vm.pop(), name.Value))
+		case compiler.OpSetAttr:
+			name := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*object.String)
+			which := int(ins[ip+3])
+			vm.currentFrame().ip += 3
+			val := vm.pop()
+			left := vm.pop()
+			err = vm.setAttr(which, left, name.Value, val)
+		case compiler.OpStruct:
+			idx := compiler.ReadUint16(ins[ip+1:])
+			n := int(ins[ip+3])
+			vm.currentFrame().ip += 3
+			err = vm.push(vm.buildStruct(vm.constants[idx].(*object.Struct), n))
+
+		case compiler.OpCall:
+			argc := int(ins[ip+1])
+			vm.currentFrame().ip++
+			err = vm.call(argc)
+		case compiler.OpTailCall:
+			argc := int(ins[ip+1])
+			vm.currentFrame().ip++
+			err = vm.tailCall(argc)
+		case compiler.OpReturnValue:
+			val := vm.pop()
+			if vm.framesIndex == 1 {
+				// a return at the top level ends the program.
+				vm.lastPopped = val
+				return nil
+			}
+			err = vm.returnValue(val)
+		case compiler.OpClosure:
+			idx := int(compiler.ReadUint16(ins[ip+1:]))
+			n := int(compiler.ReadUint16(ins[ip+3:]))
+			vm.currentFrame().ip += 4
+			err = vm.pushClosure(idx, n)
+		case compiler.OpCurrentClosure:
+			err = vm.push(vm.currentFrame().cl)
+		case compiler.OpYield:
+			err = vm.yield(vm.pop())
+
+		case compiler.OpGetIter:
+			err = vm.pushResult(eval.GetIterator(vm.pop(), vm.env))
+		case compiler.OpIterNext:
+			item := object.NextItem(vm.stack[vm.sp-1].(object.Iterator))
+			if item == nil {
+				vm.pop()
+				vm.currentFrame().ip = int(compiler.ReadUint16(ins[ip+1:])) - 1
+			} else {
+				vm.currentFrame().ip += 2
+				err = vm.pushResult(item)
+			}
+
+		case compiler.OpTry:
+			addr := int(compiler.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			vm.handlers = append(vm.handlers, handler{vm.framesIndex, vm.sp, addr})
+		case compiler.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpRaise:
+			err = object.Raise(vm.pop())
+
+		case compiler.OpImport:
+			info := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*compiler.Module)
+			vm.currentFrame().ip += 2
+			if vm.imported[info] {
+				err = vm.push(object.NONE)
+			} else {
+				vm.imported[info] = true
+				if err = vm.push(&object.Closure{Fn: info.Init}); err == nil {
+					err = vm.call(0)
+				}
+			}
+		case compiler.OpModule:
+			info := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*compiler.Module)
+			vm.currentFrame().ip += 2
+			err = vm.push(&module{info: info, globals: vm.globals})
+
+		default:
+			return object.NewError("vm: unknown opcode %d", op)
+		}
+		if err != nil {
+			vm.trace(err, stop)
+			if !vm.catch(err, stop) {
+				return err
+			}
+		}
+		if vm.framesIndex == stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// invoke : call fn from Go code, such as a builtin, running the vm until it
+// returns. An error leaving fn is returned rather than caught by the try
+// statements around the Go code.
+func (vm *VM) invoke(fn object.Object, args []object.Object) object.Object {
+	base, sp, handlers := vm.framesIndex, vm.sp, len(vm.handlers)
+	err := vm.push(fn)
+	for _, arg := range args {
+		if err == nil {
+			err = vm.push(arg)
+		}
+	}
+	if err == nil {
+		err = vm.call(len(args))
+	}
+	if err == nil && vm.framesIndex > base {
+		err = vm.run(base)
+	}
+	if err != nil {
+		vm.framesIndex, vm.sp, vm.handlers = base, sp, vm.handlers[:handlers]
+		return err
+	}
+	return vm.pop()
+}
+
+// catches : report whether a try statement active above the frame at index
+// stop catches err.
+func (vm *VM) catches(err *object.Error, stop int) bool {
+	return !err.Uncatchable() && len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].framesIndex > stop
+}
+
+// trace : add the frames err unwinds before it is caught, or returned by the
+// run stopping at the frame at index stop, to its traceback. The frames it
+// leaves are named like eval names them, the one catching it is not.
+func (vm *VM) trace(err *object.Error, stop int) {
+	caught := -1
+	if vm.catches(err, stop) {
+		caught = vm.handlers[len(vm.handlers)-1].framesIndex - 1
+		stop = caught
+	}
+	for i := vm.framesIndex - 1; i >= stop; i-- {
+		frame := vm.frames[i]
+		err.AddFrame(frame.cl.Fn.PosAt(frame.ip))
+		if i == caught {
+			break
+		}
+		err.NameFrames(frame.name())
+		if frame.tail != nil {
+			err.Trace = append(err.Trace, *frame.tail)
+		}
+	}
+}
+
+// catch : unwind to the innermost try statement and jump to its handler with
+// the Exception pushed. Returns false when no try statement is active above
+// the frame at index stop, or the error cannot be caught.
+func (vm *VM) catch(err *object.Error, stop int) bool {
+	if !vm.catches(err, stop) {
+		return false
+	}
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.framesIndex = h.framesIndex
+	vm.sp = h.sp
+	vm.currentFrame().ip = h.ip - 1
+	return vm.push(&object.Exception{Err: err}) == nil
+}
+
+// dropHandlers : remove the handlers of frames which have returned.
+func (vm *VM) dropHandlers() {
+	n := len(vm.handlers)
+	for n > 0 && vm.handlers[n-1].framesIndex > vm.framesIndex {
+		n--
+	}
+	vm.handlers = vm.handlers[:n]
+}
+
+func (vm *VM) push(obj object.Object) *object.Error {
+	if vm.sp >= StackSize {
+		return object.NewRecursionError()
+	}
+	vm.stack[vm.sp] = obj
+	vm.sp++
+	return nil
+}
+
+// pushVar : push the value of a variable, locals which have a slot but have
+// not been set yet read as nil.
+func (vm *VM) pushVar(obj object.Object) *object.Error {
+	if obj == nil {
+		return object.NewError("Identifier not found: variable used before assignment")
+	}
+	return vm.push(obj)
+}
+
+// pushResult : push the result of an operation unless it failed. Errors end
+// execution just as they propagate to the top of eval.Eval.
+func (vm *VM) pushResult(obj object.Object) *object.Error {
+	if err, ok := obj.(*object.Error); ok {
+		return err
+	}
+	return vm.push(obj)
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	obj := vm.stack[vm.sp]
+	vm.lastPopped = obj
+	return obj
+}
+
+func (vm *VM) binary(which int, left, right object.Object) object.Object {
+	// fast path for the most common case of integer arithmetic.
+	if l, ok := left.(*object.Int); ok {
+		if r, ok := right.(*object.Int); ok {
+			switch compiler.BinaryOperators[which] {
+			case "+":
+				return l.Add(r)
+			case "-":
+				return l.Sub(r)
+			case "*":
+				return l.Mul(r)
+			case "<":
+				return object.NewBool(l.Cmp(r) < 0)
+			case ">":
+				return object.NewBool(l.Cmp(r) > 0)
+			case "<=":
+				return object.NewBool(l.Cmp(r) <= 0)
+			case ">=":
+				return object.NewBool(l.Cmp(r) >= 0)
+			case "==":
+				return object.NewBool(l.Cmp(r) == 0)
+			case "!=":
+				return object.NewBool(l.Cmp(r) != 0)
+			}
+		}
+	}
+	return binaryOps[which].EvalBinary(vm.env, left, right)
+}
+
+func (vm *VM) buildDict(n int) object.Object {
+	d := object.NewDict()
+	for i := vm.sp - 2*n; i < vm.sp; i += 2 {
+		if res := d.SetItem(vm.stack[i], vm.stack[i+1]); isError(res) {
+			return res
+		}
+	}
+	vm.sp -= 2 * n
+	return d
+}
+
+func (vm *VM) setIndex(which int, left, key, val object.Object) *object.Error {
+	if _, ok := left.(object.Iterable); !ok {
+		return object.NewError("Index assignment error: wrong type")
+	}
+	if which > 0 {
+		item := object.GetItem(left, key)
+		if err, ok := item.(*object.Error); ok {
+			return err
+		}
+		val = vm.binary(which-1, item, val)
+		if err, ok := val.(*object.Error); ok {
+			return err
+		}
+	}
+	if err, ok := object.SetItem(vm.env.Runtime(), left, key, val).(*object.Error); ok {
+		return err
+	}
+	return nil
+}
+
+func (vm *VM) setAttr(which int, left object.Object, name string, val object.Object) *object.Error {
+	attr, ok := left.(object.Attributable)
+	if !ok {
+		return object.NewError("'%s' has no attribute '%s'", left.Type(), name)
+	}
+	if which > 0 {
+		current := attr.GetAttr(name)
+		if err, ok := current.(*object.Error); ok {
+			return err
+		}
+		val = vm.binary(which-1, current, val)
+		if err, ok := val.(*object.Error); ok {
+			return err
+		}
+	}
+	if err, ok := attr.SetAttr(name, val).(*object.Error); ok {
+		return err
+	}
+	return nil
+}
+
+func (vm *VM) buildStruct(template *object.Struct, n int) *object.Struct {
+	st := object.NewStruct(template.Name, template.Fields)
+	for i := vm.sp - 2*n; i < vm.sp; i += 2 {
+		st.Methods[vm.stack[i].(*object.String).Value] = vm.stack[i+1]
+	}
+	vm.sp -= 2 * n
+	return st
+}
+
+// returnValue : pop the current frame and push val as the result of its call.
+// The type of val is checked once the frame is popped, so an error is raised
+// at the call like it is by eval.
+func (vm *VM) returnValue(val object.Object) *object.Error {
+	frame := vm.popFrame()
+	vm.dropHandlers()
+	vm.sp = frame.basePointer - 1
+	if fn := frame.cl.Fn; !fn.Generator {
+		if err := object.CheckReturnType(fn.Name, fn.ReturnT, val); err != nil {
+			return err
+		}
+	}
+	return vm.push(val)
+}
+
+// unbindMethod : replace a BoundMethod being called with its function and
+// pass the receiver as the first argument, returning the new argc.
+func (vm *VM) unbindMethod(argc int) (int, *object.Error) {
+	method, ok := vm.stack[vm.sp-1-argc].(*object.BoundMethod)
+	if !ok {
+		return argc, nil
+	}
+	// make room to pass the receiver as the first argument.
+	if vm.sp >= StackSize {
+		return argc, object.NewRecursionError()
+	}
+	copy(vm.stack[vm.sp-argc+1:vm.sp+1], vm.stack[vm.sp-argc:vm.sp])
+	vm.stack[vm.sp-argc] = method.Receiver
+	vm.stack[vm.sp-1-argc] = method.Fn
+	vm.sp++
+	return argc + 1, nil
+}
+
+// tailCall : call a closure reusing the current frame. Other functions are
+// called normally and their result returned from the current frame.
+func (vm *VM) tailCall(argc int) *object.Error {
+	argc, err := vm.unbindMethod(argc)
+	if err != nil {
+		return err
+	}
+	callee, ok := vm.stack[vm.sp-1-argc].(*object.Closure)
+	if !ok || callee.Fn.Generator {
+		if err := vm.call(argc); err != nil {
+			return err
+		}
+		return vm.returnValue(vm.pop())
+	}
+	fn := callee.Fn
+	frame := vm.currentFrame()
+	if err := checkArgs(fn, vm.stack[vm.sp-argc:vm.sp]); err != nil {
+		// only the call which failed is in the traceback.
+		frame.tail = nil
+		return err
+	}
+	if frame.basePointer+fn.NumLocals >= StackSize {
+		return object.NewRecursionError()
+	}
+	frame.tail = &object.TraceFrame{Pos: frame.cl.Fn.PosAt(frame.ip), Function: frame.name()}
+	// move the callee and its args over those of the current call.
+	copy(vm.stack[frame.basePointer-1:], vm.stack[vm.sp-1-argc:vm.sp])
+	frame.cl, frame.ip = callee, -1
+	vm.sp = frame.basePointer + fn.NumLocals
+	for i := frame.basePointer + argc; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	return nil
+}
+
+func (vm *VM) call(argc int) *object.Error {
+	argc, err := vm.unbindMethod(argc)
+	if err != nil {
+		return err
+	}
+	switch callee := vm.stack[vm.sp-1-argc].(type) {
+	case *object.Closure:
+		fn := callee.Fn
+		if err := checkArgs(fn, vm.stack[vm.sp-argc:vm.sp]); err != nil {
+			return err
+		}
+		if fn.Generator {
+			g := vm.newGenerator(callee, vm.stack[vm.sp-argc:vm.sp])
+			vm.sp = vm.sp - argc - 1
+			if err := object.CheckReturnType(fn.Name, fn.ReturnT, g); err != nil {
+				return err
+			}
+			return vm.push(g)
+		}
+		if vm.framesIndex >= MaxFrames || vm.sp-argc+fn.NumLocals >= StackSize {
+			return object.NewRecursionError()
+		}
+		frame := NewFrame(callee, vm.sp-argc)
+		vm.pushFrame(frame)
+		vm.sp = frame.basePointer + fn.NumLocals
+		// clear locals left over from previous calls.
+		for i := frame.basePointer + argc; i < vm.sp; i++ {
+			vm.stack[i] = nil
+		}
+		return nil
+	case *object.Builtin:
+		args := make([]object.Object, argc)
+		copy(args, vm.stack[vm.sp-argc:vm.sp])
+		result := callee.Call(vm.env, args)
+		vm.sp = vm.sp - argc - 1
+		if result == nil {
+			result = object.NONE
+		}
+		return vm.pushResult(result)
+	case *object.Struct:
+		args := make([]object.Object, argc)
+		copy(args, vm.stack[vm.sp-argc:vm.sp])
+		vm.sp = vm.sp - argc - 1
+		return vm.pushResult(callee.New(args))
+	default:
+		return object.NewError("not a function: %s", callee.Type())
+	}
+}
+
+// checkArgs : return an Error unless args match the number and annotated
+// types of the parameters of fn.
+func checkArgs(fn *object.CompiledFunction, args []object.Object) *object.Error {
+	if len(args) != fn.NumParams {
+		return object.NewError("Wrong number of function args. Want=%d, Got=%d.",
+			fn.NumParams, len(args))
+	}
+	name := fn.Name
+	if fn.IsLambda {
+		name = "<lambda>"
+	}
+	return object.CheckArgTypes(name, fn.ParamTypes, args)
+}
+
+func (vm *VM) pushClosure(idx, n int) *object.Error {
+	fn, ok := vm.constants[idx].(*object.CompiledFunction)
+	if !ok {
+		return object.NewError("not a function: %s", vm.constants[idx].Type())
+	}
+	free := make([]object.Object, n)
+	copy(free, vm.stack[vm.sp-n:vm.sp])
+	vm.sp -= n
+	return vm.push(&object.Closure{Fn: fn, Free: free})
+}
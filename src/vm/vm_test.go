@@ -0,0 +1,427 @@
+package vm
+
+import (
+	"dito/src/ast"
+	"dito/src/compiler"
+	"dito/src/eval"
+	"dito/src/object"
+	"dito/src/parser"
+	"dito/src/scanner"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// backendTests : programs run on both the tree walking evaluator and the vm,
+// the results of both must match.
+var backendTests = []struct {
+	input    string
+	expected string
+}{
+	{"1 + 2 * 3", "7"},
+	{"-(2.5) * 2", "-5"},
+	{"not 0", "true"},
+	{`"con" ++ "cat"`, "concat"},
+	{"[1, 2] ++ [3]", "[1, 2, 3]"},
+	{"3 in [1, 2, 3]", "true"},
+	{"1 if 1 > 2 else 2", "2"},
+	{"let x = 10; let mut y = x; y *= 2; y", "20"},
+	{"let a = [1, 2, 3]; a[1] += 5; a", "[1, 7, 3]"},
+	{"let a = [1, 2, 3, 4]; a[1:3]", "[2, 3]"},
+	{`let d = {"a": 1}; d["b"] = 2; d["a"] + d["b"]`, "3"},
+	{"let mut s = 0; for i in [1, 2, 3] { s += i }; s", "6"},
+	{"let mut i = 0; for i < 5 { i += 1 }; i", "5"},
+	{"if false { 1 } else { 2 }", "2"},
+	{"if false { 1 }", "None"},
+	{"def f(a, b) { return a - b }; f(5, 3)", "2"},
+	{"def f(a) { a * 2 }; f(4)", "8"},
+	{"let fib = def(n) -> n if n < 2 else fib(n-1) + fib(n-2); fib(15)", "610"},
+	{"def adder(a) { def add(b) { a + b }; return add }; adder(1)(2)", "3"},
+	{"let c = def(a) -> def(b) -> def(c) -> a + b + c; c(1)(2)(3)", "6"},
+	{"def f() { let r = def(n) -> 0 if n == 0 else r(n - 1); r(3) }; f()", "0"},
+	{"def f() { for i in [1, 2, 3] { if i == 2 { return i } } }; f()", "2"},
+	{"def early() { return 1; 2 }; early()", "1"},
+	{"let mut g = 1; def f() { nonlocal g; g = 5; g }; [f(), g]", "[5, 5]"},
+	{"def f() { return g }; let g = 7; f()", "7"},
+	{"len([1, 2, 3]) + int(2.5)", "5"},
+	{"type(def(x) -> x)", "Lambda"},
+	{"error(\"boom\"); 1", "Error, boom"},
+	{"1 + \"a\"", "Error, mis matched types: Int, String"},
+	{"undefined", "Error, Identifier not found: 'undefined'"},
+	{"def f(a) { a }; f(1, 2)", "Error, Wrong number of function args. Want=1, Got=2."},
+	{"5(1)", "Error, not a function: Int"},
+	{`try { int("a") } catch e { string(e) }`, "Argument to Int not supported, got String"},
+	{"try { [1][5] } catch e { type(e) }", "Exception"},
+	{`try { raise "bad" } catch e { e }`, "Exception, bad"},
+	{"try { 1 } catch { 2 }", "1"},
+	{`def f() { raise "x" }; try { f() } catch { 2 }`, "2"},
+	{`try { try { raise "a" } catch e { raise e } } catch e { string(e) }`, "a"},
+	{"def f() { try { return 1 } catch { 2 }; 3 }; f()", "1"},
+	{`def f(n) { try { raise "x" } catch { n } }; f(1) + f(2)`, "3"},
+	{`def f() { try { return g() } catch { 0 } }; def g() { raise "x" }; f()`, "0"},
+	{"raise 1", "Error, Argument to `raise` not supported, got Int"},
+	{"let mut s = 0; for i in [1, 2, 3, 4] { if i == 3 { break }; s += i }; s", "3"},
+	{"let mut s = 0; for i in [1, 2, 3, 4] { if i % 2 == 0 { continue }; s += i }; s", "4"},
+	{"let mut i = 0; for true { i += 1; if i == 5 { break } }; i", "5"},
+	{"let mut i = 0; let mut s = 0; for i < 6 { i += 1; if i < 4 { continue }; s += i }; s", "15"},
+	{"let mut s = 0; for i in [1, 2] { for j in [1, 2, 3] { if j > i { break }; s += j } }; s", "4"},
+	{"def f() { for i in [1, 2, 3] { if i == 2 { break } }; 7 }; f()", "7"},
+	{"def f() { for x in [1, 2] { x * 10 } }; f()", "20"},
+	{"def f() { let mut i = 0; for i < 3 { i += 1; i * 2 } }; f()", "6"},
+	{"def f() { for x in [1, 2] { if x == 2 { break }; x } }; f()", "None"},
+	{"def f() { for x in [1, 2, 3] { if x == 2 { continue }; x } }; f()", "3"},
+	{"def f() { for x in [1, 2] { if x == 2 { continue }; x } }; f()", "None"},
+	{"def f() { for x in [] { x } }; f()", "None"},
+	{"for x in [1, 2] { for y in [3, 4] { x + y } }", "6"},
+	{"{[1]: 2}", "Error, type 'Array' is not hashable"},
+	{"{1: [1][5]}", "Error, index error"},
+	{"let mut i = 0; for true { i += 1; try { if i == 3 { break } } catch { } }; i", "3"},
+	{"struct P { x, y }; P(1, 2)", "P(x=1, y=2)"},
+	{"struct P { x, y }; let p = P(1, 2); p.x + p.y", "3"},
+	{"struct P { x }; let p = P(1); p.x = 5; p.x *= 2; p.x", "10"},
+	{"struct P { x; def get(self) { self.x }; def add(self, n) { return P(self.x + n) } }; P(1).add(2).get()", "3"},
+	{"struct P { x }; [type(P(1)), type(P)]", `["P", "Struct"]`},
+	{"struct P { x }; struct Q { x }; [P(1) == P(1), P(1) == P(2), P(1) == Q(1), P(1) != P(2)]", "[true, false, false, true]"},
+	{`struct P { x }; let d = {P(1): "a"}; d[P(1)]`, "a"},
+	{"struct P { x }; P(1).y", "Error, 'P' has no attribute 'y'"},
+	{"struct P { x }; P(1, 2)", "Error, Wrong number of args to function P. Want=1. Got=2."},
+	{"let n = 1; n.x", "Error, 'Int' has no attribute 'x'"},
+	{"len(5)", "Error, Argument 1 to function len not supported. Want=Iter. Got=Int."},
+	{"len()", "Error, Wrong number of args to function len. Want=1. Got=0."},
+	{`range(1, "5")`, "Error, Argument 2 to function range not supported. Want=Int. Got=String."},
+	{"[abs(-2), int(cos(0))]", "[2, 1]"},
+	{"def count(n, acc) { if n == 0 { return acc }; return count(n - 1, acc + 1) }; count(100000, 0)", "100000"},
+	{"let down = def(n) -> n if n == 0 else down(n - 1); down(100000)", "0"},
+	{"def even(n) { return true if n == 0 else odd(n - 1) }; def odd(n) { return false if n == 0 else even(n - 1) }; even(100001)", "false"},
+	{"struct C { n; def down(self, i) { return self.n if i == 0 else self.down(i - 1) } }; C(7).down(100000)", "7"},
+	{"def f(a) { return len(a) }; def g(a) { return f(a) }; g([1, 2])", "2"},
+	{"def f() { return P(1) }; struct P { x }; f().x", "1"},
+	{`let mut s = []; for c in "ab" { s = s ++ [c] }; s`, "['a', 'b']"},
+	{`let d = {"k": 1}; let mut s = []; for k in d { s = s ++ [k, d[k]] }; s`, `["k", 1]`},
+	{"struct Count { n, i; def done(self) { self.i >= self.n }; def next(self) { self.i += 1; self.i } }; let mut s = 0; for x in Count(3, 0) { s += x }; s", "6"},
+	{"struct Bag { items; def iter(self) { iter(self.items) } }; let mut s = 0; for x in Bag([1, 2]) { s += x }; s", "3"},
+	{"let it = iter([1, 2]); [next(it), done(it), next(it), done(it), type(it)]", `[1, false, 2, true, "Iterator"]`},
+	{"next(iter([]))", "Error, next called on an exhausted Iterator"},
+	{`array(iter("ab"))`, "['a', 'b']"},
+	{"struct C { n; def done(self) { self.n == 0 }; def next(self) { self.n -= 1; self.n } }; array(C(3))", "[2, 1, 0]"},
+	{"for x in 5 { }", "Error, 'Int' is not iterable"},
+	{"struct P { x }; for x in P(1) { }", "Error, 'P' is not iterable, it needs an iter method or next and done methods"},
+	{`struct B { x; def done(self) { false }; def next(self) { raise "bad" } }; try { for x in B(0) { } } catch e { string(e) }`, "bad"},
+	{`struct B { x; def done(self) { raise "worse" }; def next(self) { 1 } }; let it = iter(B(0)); try { next(it) } catch e { string(e) }`, "worse"},
+	{"def first(xs) { for x in xs { return x } }; first(iter([4, 5]))", "4"},
+	{"let mut i = 0; for i < 3 { i += 1; try { continue } catch { } }; try { raise \"x\" } catch { i }", "3"},
+	{"def count(n) { let mut i = 0; for i < n { yield i; i += 1 } }; array(count(4))", "[0, 1, 2, 3]"},
+	{"def nat() { let mut i = 0; for true { yield i; i += 1 } }; let mut s = 0; for x in nat() { if x > 4 { break }; s += x }; s", "10"},
+	{"def g() { yield 1; yield 2 }; let it = g(); [next(it), done(it), next(it), done(it), type(it)]", `[1, false, 2, true, "Iterator"]`},
+	{"def g() { yield 1 }; let it = g(); next(it); next(it)", "Error, next called on an exhausted Iterator"},
+	{"let n = [0]; def g() { n[0] += 1; yield 0; n[0] += 1 }; let it = g(); let a = n[0]; next(it); [a, n[0]]", "[0, 1]"},
+	{"def g() { yield 1; return 5; yield 2 }; array(g())", "[1]"},
+	{"def tree(n) { if n == 0 { return 0 }; for x in tree(n - 1) { yield x }; yield n }; array(tree(3))", "[1, 2, 3]"},
+	{"def sq(xs) { for x in xs { yield x * x } }; def evens(n) { for i in range(0, n) { if i % 2 == 0 { yield i } } }; array(sq(evens(7)))", "[0, 4, 16, 36]"},
+	{`def g() { yield 1; raise "bad" }; let mut s = []; try { for x in g() { s = s ++ [x] } } catch e { s = s ++ [string(e)] }; s`, `[1, "bad"]`},
+	{`def g(xs) { for x in xs { try { yield x; raise "r" } catch e { yield string(e) } } }; array(g([1, 2]))`, `[1, "r", 2, "r"]`},
+	{"def g() { yield next(it) }; let it = g(); next(it)", "Error, generator g is already running"},
+	{"struct Box { items; def iter(self) { for x in self.items { yield x * 10 } } }; array(Box([1, 2]))", "[10, 20]"},
+	{"def g() { yield 1 }; def f() { return g() }; array(f())", "[1]"},
+	{"def g(a) { yield a }; g()", "Error, Wrong number of function args. Want=1, Got=0."},
+	{"[range(4), range(1, 9, 2), range(5, 0, -1), type(range(1))]", `[range(0, 4), range(1, 9, 2), range(5, 0, -1), "Range"]`},
+	{"let r = range(1, 10, 3); [len(r), r[0], r[2], array(r)]", "[3, 1, 7, [1, 4, 7]]"},
+	{"[array(range(5, 0, -2)), len(range(3, 1)), len(range(1, 3, -1)), len(range(0, 1 << 40))]", "[[5, 3, 1], 0, 0, 1099511627776]"},
+	{"[4 in range(0, 10, 2), 5 in range(0, 10, 2), 10 in range(0, 10, 2), 3 in range(5, 0, -1), 0 in range(5, 0, -1), \"a\" in range(3)]", "[true, false, false, true, false, false]"},
+	{"[range(0, 10)[2:5], range(10, 0, -2)[1:3], array(range(10, 0, -2)[1:3])]", "[range(2, 5), range(8, 4, -2), [8, 6]]"},
+	{"[range(3) == range(0, 3), range(2, 2) == range(5, 1), range(3) != range(1, 3)]", "[true, true, true]"},
+	{"let mut s = 0; for i in range(0, 1 << 40) { if i == 4 { break }; s += i }; s", "6"},
+	{"let mut s = []; for i in range(3, 0, -1) { s = s ++ [i] }; s", "[3, 2, 1]"},
+	{"[[0] ++ range(1, 3), range(2) ++ [9]]", "[[0, 1, 2], [0, 1, 9]]"},
+	{"range(3)[5]", "Error, index error"},
+	{"not [1][5]", "Error, index error"},
+	{"struct P { x }; [{P(1): 2}[P(1)], {P(P(\"a\")): 3}[P(P(\"a\"))]]", "[2, 3]"},
+	{"struct P { x }; {P(P([1])): 1}", "Error, type 'Instance' is not hashable"},
+	{`struct P { x }; [P(1) == P(1.0), P(P(decimal("0.5"))) == P(P(rational(1, 2))), P(1) != P(1.0), P(1) == P("1"), P(1) != P("1")]`, "[true, true, false, false, true]"},
+	{"struct P { x }; let p = P(0); let q = P(p); p.x = q; q.x = p; [p == q, p == p, p != P(P(1))]", "[true, true, true]"},
+	{"struct P { x }; let p = P(0); p.x = p; {1: 2}[p]", "Error, type 'Instance' is not hashable"},
+	{`[{1.5: 1}[decimal("1.5")], {1: 2}[1.0], {rational(3, 2): 3}[1.5], {decimal("2.00"): 4}[2], {1 << 70: 5}[float(1 << 70)], {0.25: 6}[rational(1, 4)]]`, "[1, 2, 3, 4, 5, 6]"},
+	{`[len({1: 0, 1.0: 1, decimal("1.0"): 2, rational(2, 2): 3}), len({0.1: 1, 0.2: 2, 1: 3, 1.5: 4})]`, "[1, 4]"},
+	{`let log = [[]]; def at(x, s) { log[0] = log[0] ++ [s]; x }; struct P { x }; let t = [[1], {}, P(0)]; t[at(0, "a")][at(0, "i")] = at(2, "v"); t[at(0, "a")][at(0, "i")] += at(3, "v"); t[at(1, "d")][at("k", "k")] = at(4, "v"); t[at(2, "p")].x += at(5, "v"); [t, log[0]]`, `[[[5], {"k": 4}, P(x=5)], ["a", "i", "v", "a", "i", "v", "d", "k", "v", "p", "v"]]`},
+	{"-[1][5]", "Error, index error"},
+	{"len(range(-9223372036854775807, 9223372036854775807))", "Error, range(-9223372036854775807, 9223372036854775807) has too many items"},
+	{"let r = range(0, 9223372036854775807); [len(r), r[len(r) - 1], 9223372036854775806 in r, -1 in r, r[len(r) - 2:len(r)]]", "[9223372036854775807, 9223372036854775806, true, false, range(9223372036854775805, 9223372036854775807)]"},
+	{"let r = range(9223372036854775807, -9223372036854775807 - 1, -3); [len(r), r[len(r) - 1], 9223372036854775804 in r, -9223372036854775806 in r, r[len(r) - 2:len(r)]]", "[6148914691236517205, -9223372036854775805, true, false, range(-9223372036854775802, -9223372036854775808, -3)]"},
+	{"let mut s = []; for i in range(9223372036854775805, 9223372036854775807) { s = s ++ [i] }; s", "[9223372036854775805, 9223372036854775806]"},
+	{"array(range(-9223372036854775807 - 1, 9223372036854775807, 4611686018427387904))", "[-9223372036854775808, -4611686018427387904, 0, 4611686018427387904]"},
+	{"let r = range(3); r[0] = 1", "Error, 'Range' does not support item assignment"},
+	{"range(0, 5, 0)", "Error, Invalid args to `range` function. step must not be 0."},
+	{"range(1, 2, 3, 4)", "Error, Wrong number of args to function range. Want=1 to 3. Got=4."},
+	{"let mut fs = []; for i in range(3) { fs = fs ++ [def() -> i] }; [fs[0](), fs[1](), fs[2]()]", "[0, 1, 2]"},
+	{"def f() { let mut fs = []; let mut i = 0; for i < 3 { let j = i; fs = fs ++ [def() -> j]; i += 1 }; fs }; let fs = f(); [fs[0](), fs[2]()]", "[0, 2]"},
+	{"def counter() { let mut n = 0; def inc() { nonlocal n; n += 1; n }; return inc }; let c = counter(); c(); c(); c()", "3"},
+	{"def f() { let mut n = 0; let get = def() -> n; n = 4; get() }; f()", "4"},
+	{"def f() { let mut n = 1; def g() { def h() { nonlocal n; n *= 10 }; h(); h() }; g(); n }; f()", "100"},
+	{"def f(n) { let mut fs = []; for i in range(2) { fs = fs ++ [def() -> n + i] }; [fs[0](), fs[1]()] }; f(10)", "[10, 11]"},
+	{"let mut x = 1; if true { x = 2; let y = 3 }; x", "2"},
+	{"let mut x = 1; if true { let mut x = 5; x = 6 }; x", "1"},
+	{"for i in range(2) { }; i", "Error, Identifier not found: 'i'"},
+	{"def f() { if true { let x = 1 }; x }; f()", "Error, Identifier not found: 'x'"},
+	{"def f() { let mut s = 0; for i in range(4) { let mut t = i; if t > 1 { s += t } }; s }; f()", "5"},
+	{`try { raise "x" } catch e { let m = string(e) }; e`, "Error, Identifier not found: 'e'"},
+	{"def g() { let mut n = 0; def bump() { nonlocal n; n += 1 }; for true { bump(); yield n } }; let it = g(); [next(it), next(it)]", "[1, 2]"},
+	{`let d = {"a": 1}; d["a"] = 5; d["a"] += 1; d`, `{"a": 6}`},
+	{"let a = freeze([1, [2]]); a[0] = 5", "Error, cannot modify frozen Array"},
+	{"let a = freeze([1, [2]]); a[1][0] += 1", "Error, cannot modify frozen Array"},
+	{`let d = freeze({"k": [1]}); d["j"] = 2`, "Error, cannot modify frozen Dict"},
+	{`let d = freeze({"k": [1]}); d ++ {"j": 2}`, "Error, cannot modify frozen Dict"},
+	{`let s = freeze("ab"); s[0] = "c"`, "Error, cannot modify frozen String"},
+	{"struct P { x }; let p = freeze(P(1)); p.x = 2", "Error, cannot modify frozen P"},
+	{"let a = freeze([1, 2, 3]); let s = a[0:2]; s[0] = 0", "Error, cannot modify frozen Array"},
+	{"let a = freeze([1, 2]); let mut b = a ++ [3]; b[0] = 0; [a, b]", "[[1, 2], [0, 2, 3]]"},
+	{"let mut a = [1]; a[0] = 2; a = freeze(a); a = [3]; a[0] = 4; a", "[4]"},
+	{"let a = [1]; a[0] = 2; a", "[2]"},
+	{"let a = [0]; a[0] = a; freeze(a); a[0][0] = 1", "Error, cannot modify frozen Array"},
+	{"let x = 1; if true { let x = 2 }; def f(y) { let x = y; x }; [x, f(3)]", "[1, 3]"},
+	{"def f(x: Int, y) -> Float { return x * y }; f(3, 1.5)", "4.5"},
+	{"def f(x: Int) { x }; f(1.5)", "Error, Argument 1 to function f not supported. Want=Int. Got=Float."},
+	{"def f(x) -> Int { return x }; f(\"a\")", "Error, Return value of function f not supported. Want=Int. Got=String."},
+	{"struct S { x; def f(self) -> Int { return self.x } }; S(\"a\").f()", "Error, Return value of function f not supported. Want=Int. Got=String."},
+	{"def g(x) { x }; def f(x) -> Int { return g(x) }; f(true)", "Error, Return value of function f not supported. Want=Int. Got=Bool."},
+	{"let f = def(s: String) -> s; f(1)", "Error, Argument 1 to function <lambda> not supported. Want=String. Got=Int."},
+	{"let x: Int = 1.5", "Error, Value of 'x' not supported. Want=Int. Got=Float."},
+	{"let mut x: Int = 1; x += 1; x = \"a\"", "Error, Value of 'x' not supported. Want=Int. Got=String."},
+	{"let mut x: Int = 1; def f() { nonlocal x; x /= 2 }; f()", "Error, Value of 'x' not supported. Want=Int. Got=Float."},
+	{"struct P { x }; def f(p: P) -> Numeric { p.x }; [f(P(1)), f(P(2.5))]", "[1, 2.5]"},
+	{"struct P { x }; struct Q { x }; def f(p: P) { p.x }; f(Q(1))", "Error, Argument 1 to function f not supported. Want=P. Got=Q."},
+	{"def g() -> Iterator { yield 1 }; let a: Any = g(); array(a)", "[1]"},
+	{"let x = 0; x != 0 and 10 / x > 1", "false"},
+	{"let a = [1]; [1 < len(a) and a[1] > 0, 0 < len(a) and a[0] > 0]", "[false, true]"},
+	{"let none = sleep(0); [true and 5, false and 5, none and 5, 0 and 5, true or 5, none or 5, false or none]", "[5, false, None, 5, true, 5, None]"},
+	{"let none = sleep(0); let mut n = 0; def bump() { nonlocal n; n += 1; true }; [false and bump(), true or bump(), true and bump(), none or bump(), n]", "[false, true, true, true, 2]"},
+	{"false or undefined_name", "Error, Identifier not found: 'undefined_name'"},
+	{"def f(n) { n > 3 or f(n + 1) }; f(0)", "true"},
+	{"[7 // 0, 1]", "Error, division by zero"},
+	{"let a = [1]; a[0] %= 0", "Error, division by zero"},
+	{`let s = "ab"; s[0] = ""`, "Error, Index Assignment Error. Value is empty"},
+	{"array(-1)", "Error, negative Array length -1"},
+	{"[1.5 == range(2), 1.5 != range(2)]", "[false, true]"},
+	{"1 ++ {}", "Error, mis matched types: Int, Dict"},
+	{`"ab"[0] // "ab"[0] == "ab"[1] // "ab"[1]`, "true"},
+	{"def f() { }; type(f())", "None"},
+	{"def fact(n) { if n < 2 { return 1 }; n * fact(n - 1) }; fact(25)", "15511210043330985984000000"},
+	{"[9223372036854775807 + 1, -9223372036854775807 - 2, 9223372036854775807 * -1 - 1]", "[9223372036854775808, -9223372036854775809, -9223372036854775808]"},
+	{"let x = 9223372036854775807 + 1; [x - 1 == 9223372036854775807, type(x), type(x - 1)]", `[true, "Int", "Int"]`},
+	{"[2 ** 100 // 2 ** 98, -(2 ** 64) % 7, 1 << 70 >> 69, -(1 << 70) >> 80, 2 ** 64 / 2 ** 63]", "[4, -2, 2, -1, 2]"},
+	{"[2 ** 70 == 2.0 ** 70, 2 ** 70 < 2 ** 71, 2 ** 64 > 1.5, -(2 ** 64) < 0]", "[true, true, true, true]"},
+	{`[int("123456789012345678901234567890") + 0, string(2 ** 64), int(1e20), abs(-(2 ** 63))]`, `[123456789012345678901234567890, "18446744073709551616", 100000000000000000000, 9223372036854775808]`},
+	{`let d = {2 ** 64: "big"}; [d[2 ** 64], d[1 << 64], 18446744073709551616 in d]`, `["big", "big", true]`},
+	{"0 ** -1", "Error, division by zero"},
+	{"range(2 ** 64)", "Error, range bound 18446744073709551616 too large"},
+	{`[decimal("1.10") + decimal("2.205"), decimal("19.99") * 3, decimal("1.50") * decimal("2.00"), -decimal("1.5")]`, "[3.305, 59.97, 3.00, -1.5]"},
+	{`[decimal(0.1) + decimal(0.2) == decimal("0.3"), 0.1 + 0.2 == 0.3, decimal("1.50") == 1.5]`, "[true, false, true]"},
+	{`[decimal(1) / 3, decimal("10.00") / 4, decimal(10) // 3, decimal("10.5") % 3, decimal(2) ** -2]`, "[0.3333333333333333333333333333, 2.50, 3, 1.5, 0.25]"},
+	{`[decimal("2.675", 2), decimal("2.665", 2), decimal("2.665", 2, "half_up"), decimal("-2.661", 2, "floor"), decimal(7, 2)]`, "[2.68, 2.66, 2.67, -2.67, 7.00]"},
+	{`decimal(1, 2, "sideways")`, "Error, unknown rounding 'sideways'"},
+	{`decimal(1, "2")`, "Error, Argument 2 to function decimal not supported. Want=Int. Got=String."},
+	{`decimal(1, 2, 3)`, "Error, Argument 3 to function decimal not supported. Want=String. Got=Int."},
+	{`decimal(1, 2, "up", 4)`, "Error, Wrong number of args to function decimal. Want=1 to 3. Got=4."},
+	{"rational(1, 2, 3)", "Error, Wrong number of args to function rational. Want=1 to 2. Got=3."},
+	{"rational(1, [2])", "Error, Argument 2 to function rational not supported. Want=Atom. Got=Array."},
+	{"range()", "Error, Wrong number of args to function range. Want=1 to 3. Got=0."},
+	{"range(1, 2.5)", "Error, Argument 2 to function range not supported. Want=Int. Got=Float."},
+	{"[rational(1, 3) + rational(1, 6), rational(1, 3) * 3, rational(2, 3) ** -2, -rational(1, 2), rational(0.75)]", "[1/2, 1, 9/4, -1/2, 3/4]"},
+	{`[rational(1, 3) < 0.5, decimal("0.5") + rational(1, 3), decimal("0.5") + 0.25, 1 + decimal("0.5"), type(decimal(1) + rational(1, 2))]`, `[true, 5/6, 0.75, 1.5, "Rational"]`},
+	{`[int(decimal("-2.7")), int(rational(7, 2)), float(rational(1, 4)), string(decimal("1.50")), decimal(rational(1, 3), 4), rational(decimal("0.25"))]`, `[-2, 3, 0.25, "1.50", 0.3333, 1/4]`},
+	{`let d = {decimal("1.5"): "a", rational(1, 2): "b"}; [d[decimal("1.50")], d[rational(2, 4)]]`, `["a", "b"]`},
+	{`[decimal(1) / 0, rational(1, 0), decimal("x"), decimal("1.5") ** decimal("0.5")]`, "Error, division by zero"},
+	{`let s = "héllo, 世界"; [len(s), s[1], s[8], s[1:4], len(s[7:9]), type(s[1])]`, `[9, 'é', '界', "éll", 2, "Char"]`},
+	{`let mut s = ""; for c in "aé世" { s = s ++ string(ord(c)) ++ " " }; s`, "97 233 19990 "},
+	{`let mut s = "héllo"; s[1] = 'e'; s[0] = "Ĥ"; s`, "Ĥello"},
+	{`let mut s = "héllo"; s[1] = "ab"`, "Error, Index Assignment Error. Length of value too long"},
+	{`["é" in "héllo", 'é' in "héllo", 'a' == "a", 'a' < 'b', "ab" ++ 'c', 'a' + 1, array("hé")]`, `[true, true, true, true, "abc", 98, ['h', 'é']]`},
+	{`[chr(233), chr(97) == 'a', ord("é"), ord('\n'), ord('\''), string('x'), {'x': 1}['x']]`, `['é', true, 233, 10, 39, "x", 1]`},
+	{`let d = {}; for c in "aba" { d[c] = 1 }; [d["a"], d['b'], len(d), {"x": 1}['x'], 'x' in {"x": 1}]`, "[1, 1, 2, 1, true]"},
+	{"chr(-1)", "Error, -1 is not a valid code point"},
+	{"chr(55296)", "Error, 55296 is not a valid code point"},
+	{`ord("ab")`, "Error, ord expected a single character, got a String of length 2"},
+	{"def f(n) { if n == 0 { return 0 }; return 1 + f(n - 1) }; f(10000000)", "Error, maximum call depth exceeded"},
+	{"def f(n) { if n == 0 { return 0 }; return 1 + f(n - 1) }; try { f(10000000) } catch e { [type(e), f(100)] }", `["RecursionError", 100]`},
+	{`decimal("1.2.3")`, "Error, Argument to Decimal not supported, got String"},
+	{`let a = [0, 1]; a[0] = a; let d = {"a": a}; d["d"] = d; struct P { x }; let p = P(0); p.x = [p, a]; [a, d["a"], p]`, `[[..., 1], [..., 1], P(x=[..., [..., 1]])]`},
+	{manyLocals(300) + "; let g = def() -> a299 + a256; [a0, a255, a256, a299, g()] }; f()", "[0, 255, 256, 299, 555]"},
+}
+
+// manyLocals : the start of a function f defining n locals, a0 to a(n-1).
+func manyLocals(n int) string {
+	var b strings.Builder
+	b.WriteString("def f() { let a0 = 0")
+	for i := 1; i < n; i++ {
+		fmt.Fprintf(&b, "; let a%d = %d", i, i)
+	}
+	return b.String()
+}
+
+func TestBackendsAgree(t *testing.T) {
+	for _, tt := range backendTests {
+		evaluated := testEval(t, tt.input)
+		if got := inspect(evaluated); got != tt.expected {
+			t.Errorf("eval of %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+		run := testRun(t, tt.input)
+		if got := inspect(run); got != tt.expected {
+			t.Errorf("vm run of %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestBackendTracebacks(t *testing.T) {
+	tests := []string{
+		"def f(x) {\n    let y = x + 1\n    len(y)\n}\ndef g(x) { f(x) + 1 }\nlet h = def(x) -> g(x)\ndef k(x) { return h(x) }\nk(1)",
+		"def f(x) { [1][x] }\nstruct B {\n    i\n    def done(self) { false }\n    def next(self) { self.i += 1; f(self.i) }\n}\nfor x in B(-1) { x }",
+		"let f = def(x) -> [1][x]\nstruct B { i; def done(self) { false }; def next(self) { f(1) } }\narray(B(0))",
+		"def f(x) { [1][x] }\ndef g() {\n    try { f(5) } catch e { traceback(e) }\n}\ng()",
+		"def gen() {\n    yield 1\n    yield [][0]\n}\ndef use() {\n    for x in gen() { x }\n}\nuse()",
+		"def f(x) -> Int { return x }\ndef g(a, b) { a }\nlet gs = [g]\ndef t(x) { return gs[0](x) }\n" +
+			"let mut s = \"\"\ntry { f(\"a\") } catch e { s += traceback(e) }\ntry { t(1) } catch e { s += traceback(e) }\ns",
+	}
+	for _, input := range tests {
+		want := evalTraceback(testEval(t, input))
+		if !strings.Contains(want, "Traceback") {
+			t.Errorf("no traceback from eval of %q. got=%q", input, want)
+		}
+		if got := evalTraceback(testRun(t, input)); got != want {
+			t.Errorf("wrong traceback from vm run of %q.\nwant=%s\ngot=%s", input, want, got)
+		}
+	}
+}
+
+// evalTraceback : the traceback of an Error, or the value of the tracebacks
+// of caught errors.
+func evalTraceback(obj object.Object) string {
+	if err, ok := obj.(*object.Error); ok {
+		return err.Traceback()
+	}
+	return inspect(obj)
+}
+
+func TestStackIsBalanced(t *testing.T) {
+	machine := newTestVM(t, "let a = [1, 2]; for x in a { for y in a { x + y } }; def f() { 1 }; f(); try { for x in a { raise x } } catch { 0 }; "+
+		"struct C { i; def done(self) { self.i > 3 }; def next(self) { self.i += 1; self.i } }; for x in C(0) { if x > 1 { break } }; "+
+		"def g() { let mut i = 0; for true { try { yield i } catch { }; i += 1 } }; for x in g() { if x > 2 { break } }")
+	if result := machine.Run(); isError(result) {
+		t.Fatalf("vm error: %s", result.Inspect())
+	}
+	if machine.sp != machine.frames[0].cl.Fn.NumLocals {
+		t.Errorf("stack not empty after run. sp=%d", machine.sp)
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	searched := t.TempDir()
+	writeFile(t, filepath.Join(dir, "shapes.dito"), "let mut count = 0\ndef area(w, h) { w * h }\nlet unit = area(1, 1)")
+	writeFile(t, filepath.Join(searched, "far.dito"), "let far = \"away\"")
+	writeFile(t, filepath.Join(dir, "once.dito"), "let items = [0]")
+	writeFile(t, filepath.Join(dir, "cycle_a.dito"), "import cycle_b")
+	writeFile(t, filepath.Join(dir, "cycle_b.dito"), "import cycle_a")
+	defer func(path []string) { object.DefaultRuntime.ImportPath = path }(object.DefaultRuntime.ImportPath)
+	object.DefaultRuntime.ImportPath = []string{searched}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import shapes; shapes.area(2, 3) + shapes.unit", "7"},
+		{"import shapes as s; [type(s), s.count]", `["Module", 0]`},
+		{"from shapes import area, unit; area(unit, 4)", "4"},
+		{"def f() { from shapes import area; area(2, 2) }; f()", "4"},
+		{"import far; far.far", "away"},
+		{"import shapes; shapes.missing", "Error, module 'shapes' has no attribute 'missing'"},
+		{"import shapes; shapes.count = 1", "Error, cannot assign to attribute 'count' of module 'shapes'"},
+		{"let area = 1; import shapes; area", "1"},
+		{"import once as a; a.items[0] += 1; def f() { import once as b; b.items }; f()", "[1]"},
+	}
+	filename := filepath.Join(dir, "main.dito")
+	sandbox := object.NewRuntime(nil, nil, nil)
+	imports := parseFile(t, filename, "import shapes")
+	denied := "permission denied: import needs the filesystem capability"
+	if got := inspect(eval.Eval(imports, object.RuntimeEnvironment(sandbox))); got != "Error, "+denied {
+		t.Errorf("eval import without the filesystem capability. got=%q", got)
+	}
+	if err := compiler.NewWithRuntime(sandbox).Compile(imports); err == nil || err.Error() != denied {
+		t.Errorf("compiled import without the filesystem capability. got=%v", err)
+	}
+	for _, tt := range tests {
+		program := parseFile(t, filename, tt.input)
+		if got := inspect(eval.Eval(program, object.InitialEnvironment())); got != tt.expected {
+			t.Errorf("eval of %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+		c := compiler.New()
+		if err := c.Compile(program); err != nil {
+			t.Fatalf("compiler error for %q: %s", tt.input, err)
+		}
+		if got := inspect(New(c.Bytecode()).Run()); got != tt.expected {
+			t.Errorf("vm run of %q. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	program := parseFile(t, filename, "import nowhere")
+	if got := inspect(eval.Eval(program, object.InitialEnvironment())); !strings.HasPrefix(got, "Error, no module named 'nowhere'") {
+		t.Errorf("wrong error for missing module. got=%q", got)
+	}
+
+	cycle := "import cycle not allowed: cycle_a.dito -> cycle_b.dito -> cycle_a.dito"
+	program = parseFile(t, filename, "import cycle_a")
+	if got := inspect(eval.Eval(program, object.InitialEnvironment())); got != "Error, "+cycle {
+		t.Errorf("wrong error for import cycle. got=%q", got)
+	}
+	if err := compiler.New().Compile(program); err == nil || err.Error() != cycle {
+		t.Errorf("wrong compile error for import cycle. got=%v", err)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func parseFile(t *testing.T, filename, input string) *ast.Program {
+	p := parser.New(scanner.InitFile(filename, input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q", len(p.Errors()), input)
+	}
+	return program
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	return obj.Inspect()
+}
+
+func testEval(t *testing.T, input string) object.Object {
+	p := parser.New(scanner.Init(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q", len(p.Errors()), input)
+	}
+	return eval.Eval(program, object.InitialEnvironment())
+}
+
+func testRun(t *testing.T, input string) object.Object {
+	return newTestVM(t, input).Run()
+}
+
+func newTestVM(t *testing.T, input string) *VM {
+	p := parser.New(scanner.Init(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q", len(p.Errors()), input)
+	}
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("compiler error for %q: %s", input, err)
+	}
+	return New(c.Bytecode())
+}